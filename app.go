@@ -4,11 +4,65 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 )
 
+// App routes requests to handlers on its own http.ServeMux. It implements http.Handler, so it can be passed to
+// http.ListenAndServe or mounted under another router. Paths are http.ServeMux patterns, such as "/posts/{id}".
+//
+// Middleware runs in order before the handler. Wrappers surround the middleware and handler, with the first wrapper
+// outermost, so they may act after the handler returns, such as to log or recover from panics. An error that reaches
+// the outermost wrapper is passed to ErrorHandler.
 type App struct {
 	ErrorHandler func(*Strand)
 	Middleware   []func(*Strand) error
+	Wrappers     []func(Handler) Handler
+
+	methods      map[string]bool
+	methodsMutex sync.RWMutex
+	mux          *http.ServeMux
+	muxOnce      sync.Once
+}
+
+// allowedMethods returns the registered methods with a route matching the path of a request. Subtree patterns, such as
+// "GET /", only match their own path, so that unknown paths are not found rather than not allowed.
+func (app *App) allowedMethods(r *http.Request) []string {
+	app.methodsMutex.RLock()
+	methods := make([]string, 0, len(app.methods)+1)
+	for method := range app.methods {
+		methods = append(methods, method)
+	}
+	app.methodsMutex.RUnlock()
+	if slices.Contains(methods, "GET") && !slices.Contains(methods, "HEAD") {
+		methods = append(methods, "HEAD")
+	}
+
+	allowed := make([]string, 0)
+	for _, method := range methods {
+		probe := *r
+		probe.Method = method
+		_, pattern := app.serveMux().Handler(&probe)
+		if _, patternPath, ok := strings.Cut(pattern, " "); ok {
+			pattern = patternPath
+		}
+		if pattern == "" || (strings.HasSuffix(pattern, "/") && pattern != r.URL.Path) {
+			continue
+		}
+		allowed = append(allowed, method)
+	}
+	slices.Sort(allowed)
+	return allowed
+}
+
+// Router registers routes by method. It is implemented by App and Group.
+type Router interface {
+	Delete(path string, handler func(*Strand) error, middleware ...func(*Strand) error)
+	Get(path string, handler func(*Strand) error, middleware ...func(*Strand) error)
+	Patch(path string, handler func(*Strand) error, middleware ...func(*Strand) error)
+	Post(path string, handler func(*Strand) error, middleware ...func(*Strand) error)
+	Put(path string, handler func(*Strand) error, middleware ...func(*Strand) error)
 }
 
 func (app *App) defaultErrorHandler(strand *Strand) {
@@ -35,48 +89,141 @@ func (app *App) defaultErrorHandler(strand *Strand) {
 	}
 }
 
-func (app *App) Get(path string, handler func(*Strand) error) {
-	app.routeRequireMethod("GET", path, handler)
+func (app *App) Delete(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("DELETE", path, handler, middleware...)
+}
+
+func (app *App) Get(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("GET", path, handler, middleware...)
+}
+
+func (app *App) Head(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("HEAD", path, handler, middleware...)
+}
+
+// Group returns a router for routes under a prefix, such as "/admin". Middleware passed to Group runs after the
+// middleware of the app, and only for routes registered through the group.
+func (app *App) Group(prefix string, middleware ...func(*Strand) error) *Group {
+	return &Group{
+		Middleware: middleware,
+		app:        app,
+		prefix:     strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+// Mount routes requests under a prefix, such as "/api", to a handler with the prefix stripped from the path. The
+// middleware of the app runs first, followed by any middleware passed to Mount, and the handler receives its context.
+func (app *App) Mount(prefix string, handler http.Handler, middleware ...func(*Strand) error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	handler = http.StripPrefix(prefix, handler)
+	app.Route(prefix+"/", func(strand *Strand) error {
+		handler.ServeHTTP(strand.Response, strand.Request().WithContext(strand.Context))
+		return nil
+	}, middleware...)
 }
 
-func (app *App) Options(path string, handler func(*Strand) error) {
-	app.routeRequireMethod("OPTIONS", path, handler)
+func (app *App) Options(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("OPTIONS", path, handler, middleware...)
 }
 
-func (app *App) Post(path string, handler func(*Strand) error) {
-	app.routeRequireMethod("POST", path, handler)
+func (app *App) Patch(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("PATCH", path, handler, middleware...)
 }
 
-func (app *App) Route(path string, handler func(*Strand) error) {
+func (app *App) Post(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("POST", path, handler, middleware...)
+}
+
+func (app *App) Put(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.routeRequireMethod("PUT", path, handler, middleware...)
+}
+
+// Route handles a pattern, such as "/posts/{id}" or "GET /posts/{id}". The middleware of the app runs first, followed
+// by the middleware passed to Route, and then the handler.
+func (app *App) Route(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	if method, _, ok := strings.Cut(path, " "); ok {
+		// Methods are recorded so that other methods on the path are answered with an Allow header.
+		app.methodsMutex.Lock()
+		if app.methods == nil {
+			app.methods = make(map[string]bool)
+		}
+		app.methods[method] = true
+		app.methodsMutex.Unlock()
+	}
+	app.serveMux().HandleFunc(path, app.handlerFunc(handler, middleware))
+}
+
+// handlerFunc runs the wrappers and middleware of the app, then the middleware of a route, and then the handler.
+func (app *App) handlerFunc(handler func(*Strand) error, middleware []func(*Strand) error) http.HandlerFunc {
 	if app.ErrorHandler == nil {
 		app.ErrorHandler = app.defaultErrorHandler
 	}
-	http.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
 		strand := &Strand{
 			Context:  context.WithValue(r.Context(), "Request", r),
 			Response: w,
+			finally:  make([]func(), 0),
 		}
-		for _, middleware := range app.Middleware {
-			if strand.Error = middleware(strand); strand.Error != nil {
-				app.ErrorHandler(strand)
-				return
+		next := Handler(func(strand *Strand) error {
+			for _, middleware := range app.Middleware {
+				if err := middleware(strand); err != nil {
+					return err
+				}
 			}
+			for _, middleware := range middleware {
+				if err := middleware(strand); err != nil {
+					return err
+				}
+			}
+			return handler(strand)
+		})
+		for i := len(app.Wrappers) - 1; i >= 0; i-- {
+			next = app.Wrappers[i](next)
 		}
-		if strand.Error = handler(strand); strand.Error != nil {
+		if strand.Error = next(strand); strand.Error != nil {
 			app.ErrorHandler(strand)
 		}
+		for i := len(strand.finally) - 1; i >= 0; i-- {
+			strand.finally[i]()
+		}
+	}
+}
+
+// routeRequireMethod routes a method and path, such as "GET /posts/{id}".
+func (app *App) routeRequireMethod(method string, path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	app.Route(method+" "+path, handler, middleware...)
+}
+
+func (app *App) serveMux() *http.ServeMux {
+	app.muxOnce.Do(func() {
+		app.mux = http.NewServeMux()
 	})
+	return app.mux
 }
 
-func (app *App) routeRequireMethod(method string, path string, handler func(*Strand) error) {
-	app.Route(path, func(strand *Strand) error {
-		if strand.Request().Method != method {
-			return ErrorMethodNotAllowed{AllowedMethod: method}
+// ServeHTTP routes a request. A path with routes for other methods only is answered with ErrorMethodNotAllowed and an
+// Allow header listing those methods, and a path without routes is not found.
+func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := app.serveMux().Handler(r); pattern == "" {
+		allowed := app.allowedMethods(r)
+		if len(allowed) == 0 {
+			http.NotFound(w, r)
+			return
 		}
-		return handler(strand)
-	})
+		joined := strings.Join(allowed, ", ")
+		app.handlerFunc(func(strand *Strand) error {
+			strand.Response.Header().Set("Allow", joined)
+			return ErrorMethodNotAllowed{AllowedMethod: joined}
+		}, nil)(w, r)
+		return
+	}
+	app.serveMux().ServeHTTP(w, r)
 }
 
 func (app *App) UseMiddleware(middleware ...func(*Strand) error) {
 	app.Middleware = append(app.Middleware, middleware...)
 }
+
+func (app *App) UseWrappers(wrappers ...func(Handler) Handler) {
+	app.Wrappers = append(app.Wrappers, wrappers...)
+}
@@ -0,0 +1,162 @@
+package trance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApp(t *testing.T) {
+	type contextKey string
+	app := &App{}
+	app.UseMiddleware(func(s *Strand) error {
+		s.Context = context.WithValue(s.Context, contextKey("user"), "alice")
+		return nil
+	})
+	app.Get("/hello", func(s *Strand) error {
+		return s.WriteHtml("Hello " + s.Context.Value(contextKey("user")).(string))
+	})
+
+	app.Put("/posts/{id}", func(s *Strand) error {
+		return s.WriteHtml("Put " + s.PathValue("id"))
+	})
+	app.Delete("/posts/{id}", func(s *Strand) error {
+		return s.WriteHtml("Delete " + s.PathValue("id"))
+	})
+
+	other := &App{}
+	other.Post("/hello", func(s *Strand) error {
+		return s.WriteHtml("Other")
+	})
+
+	mounted := http.NewServeMux()
+	mounted.HandleFunc("/path", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + " " + r.Context().Value(contextKey("user")).(string)))
+	})
+	app.Mount("/mounted/", mounted)
+	app.Mount("/other", other)
+
+	expected := []struct {
+		body   string
+		method string
+		path   string
+		status int
+	}{
+		{body: "Hello alice", method: "GET", path: "/hello", status: http.StatusOK},
+		{body: "Method not allowed. Must be GET, HEAD\n", method: "POST", path: "/hello", status: http.StatusMethodNotAllowed},
+		{body: "/path alice", method: "GET", path: "/mounted/path", status: http.StatusOK},
+		{body: "Other", method: "POST", path: "/other/hello", status: http.StatusOK},
+		{body: "404 page not found\n", method: "GET", path: "/missing", status: http.StatusNotFound},
+		{body: "Put 5", method: "PUT", path: "/posts/5", status: http.StatusOK},
+		{body: "Delete 6", method: "DELETE", path: "/posts/6", status: http.StatusOK},
+		{body: "Method not allowed. Must be DELETE, PUT\n", method: "GET", path: "/posts/7", status: http.StatusMethodNotAllowed},
+	}
+	for _, e := range expected {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, httptest.NewRequest(e.method, e.path, nil))
+		if recorder.Code != e.status || recorder.Body.String() != e.body {
+			t.Errorf("%s %s: expected %d '%s', got %d '%s'", e.method, e.path, e.status, e.body, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("PATCH", "/posts/1", nil))
+	if allow := recorder.Header().Get("Allow"); allow != "DELETE, PUT" {
+		t.Errorf("Expected Allow header 'DELETE, PUT', got '%s'", allow)
+	}
+
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("GET", "/missing", nil))
+	if allow := recorder.Header().Get("Allow"); allow != "" {
+		t.Errorf("Expected no Allow header for a missing path, got '%s'", allow)
+	}
+
+	recorder = httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(recorder, httptest.NewRequest("GET", "/hello", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Error("Expected routes not to be registered on http.DefaultServeMux")
+	}
+}
+
+func TestAppMethodNotAllowed(t *testing.T) {
+	app := &App{}
+	app.Get("/", func(s *Strand) error {
+		return s.WriteHtml("Index")
+	})
+	app.Get("/posts/{id}", func(s *Strand) error {
+		return s.WriteHtml("Post " + s.PathValue("id"))
+	})
+	app.Get("/posts/new", func(s *Strand) error {
+		return s.WriteHtml("New post")
+	})
+	app.Post("/posts/new", func(s *Strand) error {
+		return s.WriteHtml("Created")
+	})
+
+	expected := []struct {
+		allow  string
+		body   string
+		method string
+		path   string
+		status int
+	}{
+		{body: "Index", method: "GET", path: "/", status: http.StatusOK},
+		{allow: "GET, HEAD", body: "Method not allowed. Must be GET, HEAD\n", method: "POST", path: "/", status: http.StatusMethodNotAllowed},
+		{body: "404 page not found\n", method: "POST", path: "/nope", status: http.StatusNotFound},
+		{body: "Post 5", method: "GET", path: "/posts/5", status: http.StatusOK},
+		{body: "New post", method: "GET", path: "/posts/new", status: http.StatusOK},
+		{body: "Created", method: "POST", path: "/posts/new", status: http.StatusOK},
+		{allow: "GET, HEAD", body: "Method not allowed. Must be GET, HEAD\n", method: "POST", path: "/posts/5", status: http.StatusMethodNotAllowed},
+		{allow: "GET, HEAD, POST", body: "Method not allowed. Must be GET, HEAD, POST\n", method: "DELETE", path: "/posts/new", status: http.StatusMethodNotAllowed},
+	}
+	for _, e := range expected {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, httptest.NewRequest(e.method, e.path, nil))
+		if recorder.Code != e.status || recorder.Body.String() != e.body {
+			t.Errorf("%s %s: expected %d '%s', got %d '%s'", e.method, e.path, e.status, e.body, recorder.Code, recorder.Body.String())
+		}
+		if allow := recorder.Header().Get("Allow"); allow != e.allow {
+			t.Errorf("%s %s: expected Allow '%s', got '%s'", e.method, e.path, e.allow, allow)
+		}
+	}
+}
+
+func TestAppMethodNotAllowedRoute(t *testing.T) {
+	app := &App{}
+	app.Route("PUT /x", func(s *Strand) error {
+		return s.WriteHtml("Put")
+	})
+	app.Get("/y", func(s *Strand) error {
+		return s.WriteHtml("Get")
+	})
+	app.Route("DELETE /y", func(s *Strand) error {
+		return s.WriteHtml("Deleted")
+	})
+	app.Group("/g").Route("PATCH /z", func(s *Strand) error {
+		return s.WriteHtml("Patched")
+	})
+
+	expected := []struct {
+		allow  string
+		body   string
+		method string
+		path   string
+		status int
+	}{
+		{body: "Put", method: "PUT", path: "/x", status: http.StatusOK},
+		{allow: "PUT", body: "Method not allowed. Must be PUT\n", method: "GET", path: "/x", status: http.StatusMethodNotAllowed},
+		{allow: "DELETE, GET, HEAD", body: "Method not allowed. Must be DELETE, GET, HEAD\n", method: "POST", path: "/y", status: http.StatusMethodNotAllowed},
+		{allow: "PATCH", body: "Method not allowed. Must be PATCH\n", method: "GET", path: "/g/z", status: http.StatusMethodNotAllowed},
+	}
+	for _, e := range expected {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, httptest.NewRequest(e.method, e.path, nil))
+		if recorder.Code != e.status || recorder.Body.String() != e.body {
+			t.Errorf("%s %s: expected %d '%s', got %d '%s'", e.method, e.path, e.status, e.body, recorder.Code, recorder.Body.String())
+		}
+		if allow := recorder.Header().Get("Allow"); allow != e.allow {
+			t.Errorf("%s %s: expected Allow '%s', got '%s'", e.method, e.path, e.allow, allow)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package trance
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SqlArray encodes a slice as a Postgres array parameter, such as '{"foo","bar"}'. Slice fields are wrapped
+// automatically on write. Use trance.Array to pass a slice to a filter as a single parameter.
+type SqlArray struct {
+	Slice any
+}
+
+func (array SqlArray) Value() (driver.Value, error) {
+	value := reflect.ValueOf(array.Slice)
+	if !value.IsValid() || (value.Kind() == reflect.Slice && value.IsNil()) {
+		return nil, nil
+	}
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("trance: unsupported type for array '%T'", array.Slice)
+	}
+	var encoded strings.Builder
+	if err := encodeArray(&encoded, value); err != nil {
+		return nil, err
+	}
+	return encoded.String(), nil
+}
+
+// Scan decodes a Postgres array into Slice, which must be a pointer to a slice.
+func (array SqlArray) Scan(src any) error {
+	return unmarshalArrayColumn(src, array.Slice)
+}
+
+func Array(slice any) SqlArray {
+	return SqlArray{Slice: slice}
+}
+
+// isArrayType reports whether a field type is stored as an array column. Byte slices are left to the driver.
+func isArrayType(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8
+}
+
+func encodeArray(encoded *strings.Builder, value reflect.Value) error {
+	encoded.WriteString("{")
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			encoded.WriteString(",")
+		}
+		if err := encodeArrayElement(encoded, value.Index(i)); err != nil {
+			return err
+		}
+	}
+	encoded.WriteString("}")
+	return nil
+}
+
+func encodeArrayElement(encoded *strings.Builder, element reflect.Value) error {
+	if element.Kind() == reflect.Pointer || element.Kind() == reflect.Interface {
+		if element.IsNil() {
+			encoded.WriteString("NULL")
+			return nil
+		}
+		element = element.Elem()
+	}
+
+	switch ev := element.Interface().(type) {
+	case driver.Valuer:
+		v, err := ev.Value()
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			encoded.WriteString("NULL")
+			return nil
+		}
+		return encodeArrayElement(encoded, reflect.ValueOf(v))
+
+	case time.Time:
+		encoded.WriteString(quoteArrayElement(ev.Format(time.RFC3339Nano)))
+		return nil
+
+	case []byte:
+		encoded.WriteString(quoteArrayElement(string(ev)))
+		return nil
+	}
+
+	switch element.Kind() {
+	case reflect.Bool:
+		encoded.WriteString(strconv.FormatBool(element.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoded.WriteString(strconv.FormatInt(element.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		encoded.WriteString(strconv.FormatUint(element.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		encoded.WriteString(strconv.FormatFloat(element.Float(), 'g', -1, element.Type().Bits()))
+	case reflect.String:
+		encoded.WriteString(quoteArrayElement(element.String()))
+	case reflect.Slice, reflect.Array:
+		return encodeArray(encoded, element)
+	default:
+		return fmt.Errorf("trance: unsupported array element type '%s'", element.Type())
+	}
+	return nil
+}
+
+func quoteArrayElement(element string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(element) + `"`
+}
+
+func unmarshalArrayColumn(src any, target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("trance: array target must be a pointer to a slice, got '%T'", target)
+	}
+
+	var text string
+	switch sv := src.(type) {
+	case nil:
+		targetValue.Elem().SetZero()
+		return nil
+	case []byte:
+		text = string(sv)
+	case string:
+		text = sv
+	default:
+		return fmt.Errorf("trance: unhandled type conversion in scan from '%T' to '%s'", src, targetValue.Elem().Type())
+	}
+
+	elements, rest, err := parseArray(text)
+	if err != nil {
+		return err
+	}
+	if rest != "" {
+		return fmt.Errorf("trance: unexpected '%s' after array '%s'", rest, text)
+	}
+	decoded, err := decodeArray(elements, targetValue.Elem().Type())
+	if err != nil {
+		return err
+	}
+	targetValue.Elem().Set(decoded)
+	return nil
+}
+
+// parseArray parses the text representation of a Postgres array. Elements are either strings, nil for NULL, or
+// []any for nested arrays.
+func parseArray(text string) ([]any, string, error) {
+	if i := strings.Index(text, "={"); i > -1 && strings.HasPrefix(text, "[") {
+		// Skip explicit dimensions, such as '[0:1]={1,2}'.
+		text = text[i+1:]
+	}
+	if !strings.HasPrefix(text, "{") {
+		return nil, text, fmt.Errorf("trance: invalid array '%s'", text)
+	}
+	text = text[1:]
+
+	elements := make([]any, 0)
+	for {
+		if strings.HasPrefix(text, "}") && len(elements) == 0 {
+			return elements, text[1:], nil
+		}
+
+		switch {
+		case strings.HasPrefix(text, "{"):
+			nested, rest, err := parseArray(text)
+			if err != nil {
+				return nil, rest, err
+			}
+			elements = append(elements, nested)
+			text = rest
+
+		case strings.HasPrefix(text, `"`):
+			var element strings.Builder
+			i := 1
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				element.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, "", fmt.Errorf("trance: unterminated array element in '%s'", text)
+			}
+			elements = append(elements, element.String())
+			text = text[i+1:]
+
+		default:
+			end := strings.IndexAny(text, ",}")
+			if end == -1 {
+				return nil, "", fmt.Errorf("trance: unterminated array '%s'", text)
+			}
+			element := strings.TrimSpace(text[:end])
+			if strings.EqualFold(element, "NULL") {
+				elements = append(elements, nil)
+			} else {
+				elements = append(elements, element)
+			}
+			text = text[end:]
+		}
+
+		if strings.HasPrefix(text, ",") {
+			text = text[1:]
+		} else if strings.HasPrefix(text, "}") {
+			return elements, text[1:], nil
+		} else {
+			return nil, "", fmt.Errorf("trance: invalid array delimiter in '%s'", text)
+		}
+	}
+}
+
+func decodeArray(elements []any, sliceType reflect.Type) (reflect.Value, error) {
+	slice := reflect.MakeSlice(sliceType, len(elements), len(elements))
+	for i, element := range elements {
+		if err := decodeArrayElement(element, slice.Index(i)); err != nil {
+			return slice, err
+		}
+	}
+	return slice, nil
+}
+
+func decodeArrayElement(element any, target reflect.Value) error {
+	if element == nil {
+		target.SetZero()
+		return nil
+	}
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	if nested, ok := element.([]any); ok {
+		if target.Kind() != reflect.Slice {
+			return fmt.Errorf("trance: unhandled nested array conversion to '%s'", target.Type())
+		}
+		decoded, err := decodeArray(nested, target.Type())
+		if err != nil {
+			return err
+		}
+		target.Set(decoded)
+		return nil
+	}
+
+	text := element.(string)
+	if scanner, ok := target.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(text)
+	}
+	if target.Type() == timeType {
+		t, err := parseArrayTime(text)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		target.SetBool(text == "t" || text == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(text, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(text, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(text, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(v)
+	case reflect.String:
+		target.SetString(text)
+	default:
+		return fmt.Errorf("trance: unhandled type conversion in scan from array element to '%s'", target.Type())
+	}
+	return nil
+}
+
+func parseArrayTime(text string) (time.Time, error) {
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z07",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("trance: invalid array timestamp '%s'", text)
+}
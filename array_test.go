@@ -0,0 +1,132 @@
+package trance
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/exp/slices"
+)
+
+func TestArrayValue(t *testing.T) {
+	nullString := "bar"
+	values := map[string]any{
+		`{"foo","b\"a\\r",""}`:     []string{"foo", `b"a\r`, ""},
+		`{1,-2,3}`:                 []int64{1, -2, 3},
+		`{1.5,2}`:                  []float64{1.5, 2},
+		`{true,false}`:             []bool{true, false},
+		`{{1,2},{3,4}}`:            [][]int{{1, 2}, {3, 4}},
+		`{NULL,"bar"}`:             []*string{nil, &nullString},
+		`{"2009-01-02T03:00:00Z"}`: []time.Time{time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC)},
+		`{NULL,"foo"}`:             []sql.NullString{{}, {String: "foo", Valid: true}},
+		`{}`:                       []string{},
+	}
+	for expected, slice := range values {
+		actual, err := Array(slice).Value()
+		if err != nil {
+			t.Fatalf("Unexpected error for '%#v': %s", slice, err)
+		}
+		if actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	}
+
+	if actual, err := Array([]string(nil)).Value(); err != nil || actual != nil {
+		t.Errorf("Expected nil slice to encode as NULL, got '%#v'", actual)
+	}
+}
+
+func TestArrayScan(t *testing.T) {
+	var labels []string
+	if err := Array(&labels).Scan([]byte(`{foo,"b\"a\\r","",NULL," x "}`)); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := []string{"foo", `b"a\r`, "", "", " x "}; !slices.Equal(labels, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, labels)
+	}
+
+	var ints [][]int64
+	if err := Array(&ints).Scan(`{{1,2},{3,4}}`); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(ints) != 2 || !slices.Equal(ints[0], []int64{1, 2}) || !slices.Equal(ints[1], []int64{3, 4}) {
+		t.Errorf("Unexpected '%#v'", ints)
+	}
+
+	var pointers []*bool
+	if err := Array(&pointers).Scan(`{t,NULL}`); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(pointers) != 2 || pointers[0] == nil || !*pointers[0] || pointers[1] != nil {
+		t.Errorf("Unexpected '%#v'", pointers)
+	}
+
+	var times []time.Time
+	if err := Array(&times).Scan(`{"2009-01-02 03:00:00+00"}`); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(times) != 1 || !times[0].Equal(time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected '%#v'", times)
+	}
+
+	for _, invalid := range []string{`foo`, `{1,2`, `{"foo}`, `{1}x`} {
+		if err := Array(&labels).Scan(invalid); err == nil {
+			t.Errorf("Expected error for '%s'", invalid)
+		}
+	}
+}
+
+func TestModelArrayFields(t *testing.T) {
+	type testArrayModel struct {
+		Id   int64    `@:"id" @primary:"true"`
+		Ids  []int64  `@:"ids"`
+		Tags []string `@:"tags"`
+	}
+	defer PurgeWeaves()
+	weave := UseWith[testArrayModel](WeaveConfig{NoCache: true})
+
+	row, err := weave.ScanMap(map[string]any{
+		"id":   int64(1),
+		"ids":  []int64{1, 2},
+		"tags": []byte(`{foo,bar}`),
+	})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 1 || !slices.Equal(row.Ids, []int64{1, 2}) || !slices.Equal(row.Tags, []string{"foo", "bar"}) {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	actual, err := weave.ToMap(&testArrayModel{Id: 1, Tags: []string{"foo"}})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if ids, err := actual["ids"].(SqlArray).Value(); err != nil || ids != "{}" {
+		t.Errorf("Expected nil slice to be an empty array, got '%#v'", actual["ids"])
+	}
+	if tags, err := actual["tags"].(SqlArray).Value(); err != nil || tags != `{"foo"}` {
+		t.Errorf(`Expected '{"foo"}', got '%#v'`, actual["tags"])
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "ids", "tags"}).AddRow(2, "{3,4}", nil))
+	rs, _ := db.Query("SELECT")
+	defer rs.Close()
+	columns, _ := rs.Columns()
+	if !rs.Next() {
+		t.Fatal("Expected a row")
+	}
+	row, err = weave.ScanColumns(rs, columns)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 2 || !slices.Equal(row.Ids, []int64{3, 4}) || row.Tags != nil {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+}
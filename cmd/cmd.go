@@ -0,0 +1,152 @@
+// Package cmd implements the trance command-line tool. Applications embed it in a main package of their own, so their
+// models, migrations, and database drivers are linked in:
+//
+//	func main() {
+//		trance.RegisterModel[models.Account]()
+//		cmd.Main(cmd.Config{Migrations: migrations.All})
+//	}
+//
+// The database is opened from the -dsn and -dialect flags, or the TRANCE_DSN and TRANCE_DIALECT environment variables.
+// Applications that call trance.UseDatabase and trance.SetDialect themselves can leave both unset.
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/evantbyrne/trance"
+	"github.com/evantbyrne/trance/mysqldialect"
+	"github.com/evantbyrne/trance/pqdialect"
+	"github.com/evantbyrne/trance/sqlitedialect"
+)
+
+type Config struct {
+	// Drivers maps dialect names to database/sql driver names, which default to "mysql", "postgres", and "sqlite3".
+	Drivers map[string]string
+	// Fixtures is the file system fixtures are loaded from. Defaults to the working directory.
+	Fixtures   fs.FS
+	Migrations []trance.Migration
+	// Stdout defaults to os.Stdout.
+	Stdout io.Writer
+}
+
+var defaultDrivers = map[string]string{
+	"mysql":    "mysql",
+	"postgres": "postgres",
+	"sqlite":   "sqlite3",
+}
+
+var dialects = map[string]trance.Dialect{
+	"mysql":    mysqldialect.MysqlDialect{},
+	"postgres": pqdialect.PqDialect{},
+	"sqlite":   sqlitedialect.SqliteDialect{},
+}
+
+const usage = `Usage: [-dsn DSN] [-dialect mysql|postgres|sqlite] COMMAND
+
+Commands:
+  fixtures dump [-o FILE] [TABLE...]
+  fixtures load PATH...
+  inspectdb [-o FILE] [-package NAME] [-tables TABLE,...]
+  makemigrations -name NAME [-drop-tables TABLE,...] [-o FILE] [-package NAME]
+  migrate down [-dry-run] [-steps N]
+  migrate status
+  migrate to [-dry-run] ID
+  migrate up [-dry-run]
+  sql [-o FILE]
+`
+
+// Main runs the command of os.Args, and exits with status 1 on errors.
+func Main(config Config) {
+	if err := Run(os.Args[1:], config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Run opens the database and runs a command.
+func Run(args []string, config Config) error {
+	if config.Stdout == nil {
+		config.Stdout = os.Stdout
+	}
+	flags := flag.NewFlagSet("trance", flag.ContinueOnError)
+	flags.SetOutput(config.Stdout)
+	flags.Usage = func() {
+		io.WriteString(config.Stdout, usage)
+	}
+	dsn := flags.String("dsn", os.Getenv("TRANCE_DSN"), "Data source name of the database. Defaults to TRANCE_DSN")
+	dialectName := flags.String("dialect", os.Getenv("TRANCE_DIALECT"), "Dialect of the database. Defaults to TRANCE_DIALECT")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("trance: missing command")
+	}
+
+	db, err := connect(*dsn, *dialectName, config.Drivers)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "fixtures":
+		return Fixtures(args, config.Fixtures, config.Stdout)
+	case "inspectdb":
+		return Inspectdb(args, config.Stdout)
+	case "makemigrations":
+		return Makemigrations(args, config.Stdout)
+	case "migrate":
+		return Migrate(args, config.Migrations, config.Stdout)
+	case "sql":
+		return Sql(args, config.Stdout)
+	}
+	flags.Usage()
+	return fmt.Errorf("trance: unknown command '%s'", command)
+}
+
+// connect registers the dialect and opens the database when they are set.
+func connect(dsn string, dialectName string, drivers map[string]string) (*sql.DB, error) {
+	if dialectName != "" {
+		dialect, ok := dialects[dialectName]
+		if !ok {
+			return nil, fmt.Errorf("trance: unknown dialect '%s'. Must be mysql, postgres, or sqlite", dialectName)
+		}
+		trance.SetDialect(dialect)
+	}
+	if dsn == "" {
+		return nil, nil
+	}
+	if dialectName == "" {
+		return nil, errors.New("trance: a dialect is required to open the database. Set -dialect or TRANCE_DIALECT")
+	}
+
+	driver, ok := drivers[dialectName]
+	if !ok {
+		driver = defaultDrivers[dialectName]
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("trance: failed to open database with driver '%s'. Drivers must be imported by the main package", driver), err)
+	}
+	trance.UseDatabase(db)
+	return db, nil
+}
+
+// writeOutput writes to a file, or to stdout when no file is set.
+func writeOutput(output string, contents string, stdout io.Writer) error {
+	if output != "" {
+		return os.WriteFile(output, []byte(contents), 0644)
+	}
+	_, err := io.WriteString(stdout, contents)
+	return err
+}
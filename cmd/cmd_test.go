@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/evantbyrne/trance"
+)
+
+type testCmdGroup struct {
+	Id   int64  `@:"id" @primary:"true"`
+	Name string `@:"name"`
+}
+
+func TestRun(t *testing.T) {
+	defer trance.PurgeWeaves()
+	db, mock, err := sqlmock.NewWithDSN("trance_cmd_run")
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	config := Config{
+		Drivers: map[string]string{"postgres": "sqlmock"},
+		Migrations: []trance.Migration{
+			trance.SqlMigration{Id: "0001_a", UpSql: "CREATE TABLE a (id INTEGER);", DownSql: "DROP TABLE a;"},
+			trance.SqlMigration{Id: "0002_b", UpSql: "CREATE TABLE b (id INTEGER);"},
+		},
+	}
+	expectLogs := func() {
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS "migrationlogs"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM "migrationlogs"`).WillReturnRows(sqlmock.NewRows([]string{"created_at", "direction", "id", "migration_type"}).
+			AddRow(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "up", 1, "0001_a"))
+	}
+
+	expectLogs()
+	mock.ExpectClose()
+	var stdout bytes.Buffer
+	config.Stdout = &stdout
+	if err := Run([]string{"-dsn", "trance_cmd_run", "-dialect", "postgres", "migrate", "status"}, config); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := "STATE    ID      APPLIED AT\n" +
+		"applied  0001_a  2024-01-02T03:04:05Z\n" +
+		"pending  0002_b  \n"
+	if stdout.String() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, stdout.String())
+	}
+
+	expectLogs()
+	mock.ExpectClose()
+	stdout.Reset()
+	t.Setenv("TRANCE_DSN", "trance_cmd_run")
+	t.Setenv("TRANCE_DIALECT", "postgres")
+	if err := Run([]string{"migrate", "up", "-dry-run"}, config); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := "-- Migrate up to 0002_b\nCREATE TABLE b (id INTEGER);\n\n"; stdout.String() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, stdout.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	errorArgs := [][]string{
+		{},
+		{"-dialect", "oracle", "sql"},
+		{"-dialect", "postgres", "unknown"},
+		{"-dialect", "postgres", "migrate"},
+		{"-dialect", "postgres", "migrate", "sideways"},
+		{"-dialect", "postgres", "migrate", "to"},
+		{"-dialect", "postgres", "fixtures", "load"},
+		{"-dialect", "postgres", "makemigrations"},
+	}
+	t.Setenv("TRANCE_DSN", "")
+	for _, args := range errorArgs {
+		if err := Run(args, config); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+func TestSql(t *testing.T) {
+	defer trance.PurgeWeaves()
+	trance.RegisterModel[testCmdGroup]()
+	var stdout bytes.Buffer
+	if err := Run([]string{"-dialect", "sqlite", "sql"}, Config{Stdout: &stdout}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := "CREATE TABLE `testcmdgroup` (\n\t`id` INTEGER PRIMARY KEY NOT NULL,\n\t`name` TEXT NOT NULL\n);\n"; !strings.Contains(stdout.String(), expected) {
+		t.Errorf("Expected '%s', got '%s'", expected, stdout.String())
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	defer trance.PurgeWeaves()
+	trance.RegisterModel[testCmdGroup]()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	trance.UseDatabase(db)
+
+	fsys := fstest.MapFS{"groups.json": {Data: []byte(`{"testcmdgroup": [{"id": 1, "name": "One"}]}`)}}
+	mock.ExpectBegin()
+	// Insert column order follows map iteration, so only the statement is matched.
+	mock.ExpectExec(`INSERT INTO "testcmdgroup"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`SELECT setval\(pg_get_serial_sequence\('"testcmdgroup"', 'id'\), MAX\("id"\)\) FROM "testcmdgroup"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	var stdout bytes.Buffer
+	if err := Run([]string{"-dialect", "postgres", "fixtures", "load", "groups.json"}, Config{Fixtures: fsys, Stdout: &stdout}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	mock.ExpectQuery(`SELECT .* FROM "testcmdgroup" ORDER BY "id" ASC`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "One"))
+	stdout.Reset()
+	if err := Run([]string{"-dialect", "postgres", "fixtures", "dump", "testcmdgroup"}, Config{Stdout: &stdout}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := "{\n\t\"testcmdgroup\": [\n\t\t{\n\t\t\t\"id\": 1,\n\t\t\t\"name\": \"One\"\n\t\t}\n\t]\n}\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, stdout.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
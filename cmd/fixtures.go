@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/evantbyrne/trance"
+)
+
+// Fixtures runs the load and dump subcommands. Load reads fixture files from fsys, or from the working directory when
+// fsys is nil. Dump writes JSON fixtures of registered models to stdout, or to the file set by the -o flag.
+func Fixtures(args []string, fsys fs.FS, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("trance: missing fixtures command. Must be load or dump")
+	}
+	flags := flag.NewFlagSet("fixtures "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	switch args[0] {
+	case "dump":
+		output := flags.String("o", "", "Write fixtures to a file instead of stdout")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		fixtures, err := trance.DumpRegisteredFixtures(flags.Args()...)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(fixtures, "", "\t")
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, string(data)+"\n", stdout)
+
+	case "load":
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			return errors.New("trance: fixtures load requires at least one file")
+		}
+		if fsys == nil {
+			fsys = os.DirFS(".")
+		}
+		if err := trance.LoadFixtures(fsys, flags.Args()...); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(stdout, "Loaded %d fixture files\n", flags.NArg())
+		return err
+	}
+	return fmt.Errorf("trance: unknown fixtures command '%s'. Must be load or dump", args[0])
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+// Inspectdb writes models for the tables of the database to stdout, or to the file set by the -o flag.
+func Inspectdb(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("inspectdb", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	output := flags.String("o", "", "Write models to a file instead of stdout")
+	packageName := flags.String("package", "models", "Package of the generated file")
+	tables := flags.String("tables", "", "Comma separated tables to inspect. Defaults to all tables")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config := trance.InspectModelsConfig{Package: *packageName}
+	if *tables != "" {
+		config.Tables = strings.Split(*tables, ",")
+	}
+	source, err := trance.InspectModels(config)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, source, stdout)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/evantbyrne/trance"
+	"github.com/evantbyrne/trance/pqdialect"
+)
+
+func TestInspectdb(t *testing.T) {
+	defer trance.PurgeWeaves()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	trance.UseDatabase(db)
+	trance.SetDialect(pqdialect.PqDialect{})
+
+	expectSchema := func() {
+		mock.ExpectQuery("SELECT c.table_name").WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "udt_name", "character_maximum_length", "numeric_precision", "numeric_scale", "is_nullable", "column_default", "primary"}).
+			AddRow("accounts", "id", "bigint", "int8", nil, 64, 0, "NO", "nextval('accounts_id_seq'::regclass)", true).
+			AddRow("accounts", "balance", "numeric", "numeric", nil, 10, 2, "NO", "0", false).
+			AddRow("accounts", "created_at", "timestamp with time zone", "timestamptz", nil, nil, nil, "NO", nil, false).
+			AddRow("accounts", "email", "character varying", "varchar", 100, nil, nil, "NO", nil, false).
+			AddRow("accounts", "group_id", "integer", "int4", nil, 32, 0, "YES", nil, false).
+			AddRow("accounts", "visits", "integer", "int4", nil, 32, 0, "NO", nil, false).
+			AddRow("groups", "id", "integer", "int4", nil, 32, 0, "NO", "nextval('groups_id_seq'::regclass)", true).
+			AddRow("groups", "tags", "ARRAY", "_text", nil, nil, nil, "NO", nil, false).
+			AddRow("groups", "uuid", "uuid", "uuid", nil, nil, nil, "NO", nil, false))
+		mock.ExpectQuery("SELECT kcu.table_name").WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "constraint_type", "foreign_table", "foreign_column"}).
+			AddRow("accounts", "email", "UNIQUE", "", "").
+			AddRow("accounts", "group_id", "FOREIGN KEY", "groups", "id"))
+	}
+
+	expected := "package models\n" +
+		"\n" +
+		"import (\n" +
+		"\t\"time\"\n" +
+		"\n" +
+		"\t\"github.com/evantbyrne/trance\"\n" +
+		")\n" +
+		"\n" +
+		"type Accounts struct {\n" +
+		"\tId        int64                         `@:\"id\" @primary:\"true\"`\n" +
+		"\tBalance   trance.Decimal                `@:\"balance\" @precision:\"10\" @scale:\"2\" @default:\"0\"`\n" +
+		"\tCreatedAt time.Time                     `@:\"created_at\" @time_zone:\"true\"`\n" +
+		"\tEmail     string                        `@:\"email\" @length:\"100\" @unique:\"true\"`\n" +
+		"\tGroup     trance.NullForeignKey[Groups] `@:\"group_id\"`\n" +
+		"\tVisits    int32                         `@:\"visits\"`\n" +
+		"}\n" +
+		"\n" +
+		"type Groups struct {\n" +
+		"\tId   int32    `@:\"id\" @primary:\"true\"`\n" +
+		"\tTags []string `@:\"tags\"`\n" +
+		"\tUuid string   `@:\"uuid\" @type:\"UUID NOT NULL\"`\n" +
+		"}\n"
+
+	expectSchema()
+	var stdout bytes.Buffer
+	if err := Inspectdb([]string{}, &stdout); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if stdout.String() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, stdout.String())
+	}
+
+	expectSchema()
+	output := filepath.Join(t.TempDir(), "models.go")
+	if err := Inspectdb([]string{"-o", output, "-package", "legacy", "-tables", "groups"}, &stdout); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	contents, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !bytes.HasPrefix(contents, []byte("package legacy\n\ntype Groups struct {")) {
+		t.Errorf("Unexpected file '%s'", contents)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
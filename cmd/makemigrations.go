@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+// Makemigrations writes a migration from the differences between registered models and the database to stdout, or to
+// the file set by the -o flag.
+func Makemigrations(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("makemigrations", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dropTables := flags.String("drop-tables", "", "Comma separated tables without a registered model to drop")
+	name := flags.String("name", "", "Go type and ID of the migration, such as Migration0002")
+	output := flags.String("o", "", "Write the migration to a file instead of stdout")
+	packageName := flags.String("package", "migrations", "Package of the generated file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("trance: makemigrations requires -name")
+	}
+
+	config := trance.MakeMigrationConfig{Name: *name, Package: *packageName}
+	if *dropTables != "" {
+		config.DropTables = strings.Split(*dropTables, ",")
+	}
+	source, err := trance.MakeMigration(config)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		_, err := fmt.Fprintln(stdout, "No changes detected")
+		return err
+	}
+	return writeOutput(*output, source, stdout)
+}
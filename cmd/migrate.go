@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/evantbyrne/trance"
+)
+
+// Migrate runs the up, down, to, and status subcommands. With the -dry-run flag, up, down, and to print the SQL of
+// each migration instead of running it.
+func Migrate(args []string, migrations []trance.Migration, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("trance: missing migrate command. Must be up, down, to, or status")
+	}
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	var dryRun *bool
+	var steps *int
+	switch args[0] {
+	case "down":
+		steps = flags.Int("steps", 1, "Number of migrations to revert")
+		fallthrough
+	case "to", "up":
+		dryRun = flags.Bool("dry-run", false, "Print SQL instead of migrating")
+	case "status":
+	default:
+		return fmt.Errorf("trance: unknown migrate command '%s'. Must be up, down, to, or status", args[0])
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var logs []string
+	var scripts []trance.MigrationScript
+	var err error
+	switch args[0] {
+	case "down":
+		if *dryRun {
+			scripts, err = trance.MigrateDownStepsDryRun(migrations, *steps)
+		} else {
+			logs, err = trance.MigrateDownSteps(migrations, *steps)
+		}
+
+	case "status":
+		return migrateStatus(migrations, stdout)
+
+	case "to":
+		if flags.NArg() != 1 {
+			return errors.New("trance: migrate to requires the ID of a migration")
+		}
+		if *dryRun {
+			scripts, err = trance.MigrateToDryRun(migrations, flags.Arg(0))
+		} else {
+			logs, err = trance.MigrateTo(migrations, flags.Arg(0))
+		}
+
+	case "up":
+		if *dryRun {
+			scripts, err = trance.MigrateUpDryRun(migrations)
+		} else {
+			logs, err = trance.MigrateUp(migrations)
+		}
+	}
+
+	for _, log := range logs {
+		fmt.Fprintln(stdout, log)
+	}
+	for _, script := range scripts {
+		fmt.Fprintln(stdout, script.String())
+	}
+	return err
+}
+
+func migrateStatus(migrations []trance.Migration, stdout io.Writer) error {
+	statuses, err := trance.MigrationStatus(migrations)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATE\tID\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := ""
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", status.State, status.Id, appliedAt)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+// Sql writes the CREATE TABLE statements of registered models to stdout, or to the file set by the -o flag. It does not
+// connect to the database.
+func Sql(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("sql", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	output := flags.String("o", "", "Write SQL to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	statements, err := trance.TableCreateSql()
+	if err != nil {
+		return err
+	}
+	var source strings.Builder
+	for _, statement := range statements {
+		source.WriteString(statement)
+		source.WriteString(";\n")
+	}
+	return writeOutput(*output, source.String(), stdout)
+}
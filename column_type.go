@@ -0,0 +1,94 @@
+package trance
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/evantbyrne/trance/templates/forms"
+)
+
+// ColumnType describes how a custom Go type is stored, scanned, encoded to JSON, and rendered on forms. Register
+// types with RegisterType before calling Use on models that have fields of that type.
+type ColumnType[T any] struct {
+	// Json converts a value to its JSON representation. Values are used as-is when nil.
+	Json func(T) any
+	// Scan converts a database or form value to T.
+	Scan func(src any) (T, error)
+	// SqlTypes maps dialects, such as pqdialect.PqDialect{}, to SQL types. Dialects add NULL constraints.
+	SqlTypes map[Dialect]string
+	// Value converts a value to a database value. Values are used as-is when nil.
+	Value func(T) (driver.Value, error)
+	// Widget renders fields of the type on forms that don't set their own widget.
+	Widget forms.Widgeter
+}
+
+type registeredType struct {
+	json     func(any) any
+	scan     func(any) (any, error)
+	sqlTypes map[Dialect]string
+	value    func(any) (driver.Value, error)
+	widget   forms.Widgeter
+}
+
+var registeredTypes = &sync.Map{}
+
+func RegisterType[T any](columnType ColumnType[T]) {
+	registered := &registeredType{
+		sqlTypes: columnType.SqlTypes,
+		widget:   columnType.Widget,
+	}
+	if columnType.Json != nil {
+		registered.json = func(value any) any {
+			return columnType.Json(value.(T))
+		}
+	}
+	if columnType.Scan != nil {
+		registered.scan = func(src any) (any, error) {
+			return columnType.Scan(src)
+		}
+	}
+	if columnType.Value != nil {
+		registered.value = func(value any) (driver.Value, error) {
+			return columnType.Value(value.(T))
+		}
+	}
+	registeredTypes.Store(reflect.TypeFor[T](), registered)
+}
+
+// RegisteredSqlType returns the SQL type registered for a Go type on a dialect.
+func RegisteredSqlType(dialect Dialect, fieldType reflect.Type) (string, bool) {
+	registered := lookupRegisteredType(fieldType)
+	if registered == nil {
+		return "", false
+	}
+	sqlType, ok := registered.sqlTypes[dialect]
+	return sqlType, ok
+}
+
+func lookupRegisteredType(fieldType reflect.Type) *registeredType {
+	if registered, ok := registeredTypes.Load(fieldType); ok {
+		return registered.(*registeredType)
+	}
+	return nil
+}
+
+// scanValue converts a source value to a registered type. Values that are already of the type are kept.
+func (registered *registeredType) scanValue(src any, fieldType reflect.Type) (reflect.Value, error) {
+	srcValue := reflect.ValueOf(src)
+	if srcValue.IsValid() && srcValue.Type().AssignableTo(fieldType) {
+		return srcValue, nil
+	}
+	if registered.scan == nil {
+		if converted, ok := convertValue(srcValue, fieldType); ok {
+			return converted, nil
+		}
+		return srcValue, fmt.Errorf("trance: unhandled type conversion in scan from '%T' to '%s'", src, fieldType)
+	}
+	value, err := registered.scan(src)
+	if err != nil {
+		return srcValue, err
+	}
+	return reflect.ValueOf(value), nil
+}
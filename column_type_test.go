@@ -0,0 +1,130 @@
+package trance
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/a-h/templ"
+	"github.com/evantbyrne/trance/templates/forms"
+)
+
+type testPoint struct {
+	X int
+	Y int
+}
+
+type testPointModel struct {
+	Id       int64     `@:"id" @primary:"true"`
+	Location testPoint `@:"location"`
+}
+
+func init() {
+	RegisterType(ColumnType[testPoint]{
+		Json: func(point testPoint) any {
+			return fmt.Sprintf("%d,%d", point.X, point.Y)
+		},
+		Scan: func(src any) (testPoint, error) {
+			var point testPoint
+			var text string
+			switch sv := src.(type) {
+			case []byte:
+				text = string(sv)
+			case string:
+				text = sv
+			default:
+				return point, fmt.Errorf("unsupported point '%T'", src)
+			}
+			_, err := fmt.Sscanf(text, "(%d,%d)", &point.X, &point.Y)
+			return point, err
+		},
+		SqlTypes: map[Dialect]string{testDialect{}: "POINT"},
+		Value: func(point testPoint) (driver.Value, error) {
+			return fmt.Sprintf("(%d,%d)", point.X, point.Y), nil
+		},
+		Widget: func(fieldData forms.FormTemplateDataField) templ.Component {
+			return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				_, err := io.WriteString(w, "<point-widget name=\""+fieldData.Key+"\"></point-widget>")
+				return err
+			})
+		},
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testPointModel](WeaveConfig{NoCache: true})
+
+	if sqlType, ok := RegisteredSqlType(testDialect{}, weave.Fields["location"].Type); !ok || sqlType != "POINT" {
+		t.Errorf("Expected 'POINT', got '%s'", sqlType)
+	}
+
+	row, err := weave.ScanMap(map[string]any{"id": int64(1), "location": "(1,2)"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Location != (testPoint{X: 1, Y: 2}) {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+	if _, err := weave.ScanMap(map[string]any{"location": "nowhere"}); err == nil {
+		t.Error("Expected error for invalid point")
+	}
+
+	actual, err := weave.ToMap(row)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if actual["location"] != "(1,2)" {
+		t.Errorf("Expected '(1,2)', got '%#v'", actual["location"])
+	}
+	assertMapDeepEquals(t, weave.ToJsonMap(row), map[string]any{"id": int64(1), "location": "1,2"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "location"}).AddRow(2, []byte("(3,4)")))
+	rs, _ := db.Query("SELECT")
+	defer rs.Close()
+	columns, _ := rs.Columns()
+	if !rs.Next() {
+		t.Fatal("Expected a row")
+	}
+	row, err = weave.ScanColumns(rs, columns)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 2 || row.Location != (testPoint{X: 3, Y: 4}) {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"location"}).AddRow("(5,6)"))
+	rs2, _ := db.Query("SELECT")
+	defer rs2.Close()
+	if !rs2.Next() {
+		t.Fatal("Expected a row")
+	}
+	data, err := ScanFieldsToMap(rs2, weave.Fields)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if data["location"] != (testPoint{X: 5, Y: 6}) {
+		t.Errorf("Unexpected '%#v'", data["location"])
+	}
+
+	form := Form[testPointModel]{Fields: []string{"location"}, Weave: weave}
+	var html bytes.Buffer
+	if err := form.Component().Render(context.Background(), &html); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(html.String(), `<point-widget name="location"></point-widget>`) {
+		t.Errorf("Expected registered widget in '%s'", html.String())
+	}
+}
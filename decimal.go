@@ -0,0 +1,97 @@
+package trance
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// Decimal stores an exact numeric value as its string representation, such as "12.50". It maps to NUMERIC on Postgres
+// and DECIMAL on MySQL, with precision and scale set by the `@precision` and `@scale` field tags. SQLite stores it as
+// TEXT, as its NUMERIC affinity would round values, and ignores the tags.
+type Decimal string
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// DecimalFromRat formats a rational number as a Decimal rounded to scale digits after the decimal point.
+func DecimalFromRat(value *big.Rat, scale int) Decimal {
+	return Decimal(value.FloatString(scale))
+}
+
+// ParseDecimal validates that value is a plain decimal number. Exponents are not accepted.
+func ParseDecimal(value string) (Decimal, error) {
+	if !decimalPattern.MatchString(value) {
+		return "", fmt.Errorf("trance: invalid decimal '%s'", value)
+	}
+	return Decimal(value), nil
+}
+
+func (decimal Decimal) JsonValue() any {
+	return string(decimal)
+}
+
+func (decimal Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(decimal))
+}
+
+// Rat returns the decimal as a rational number for exact arithmetic.
+func (decimal Decimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(decimal))
+}
+
+func (decimal *Decimal) Scan(src any) error {
+	var value string
+	switch sv := src.(type) {
+	case nil:
+		*decimal = ""
+		return nil
+	case []byte:
+		value = string(sv)
+	case string:
+		value = sv
+	case int64:
+		value = strconv.FormatInt(sv, 10)
+	case float64:
+		value = strconv.FormatFloat(sv, 'f', -1, 64)
+	default:
+		return fmt.Errorf("trance: unhandled type conversion in scan from '%T' to 'trance.Decimal'", src)
+	}
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	*decimal = parsed
+	return nil
+}
+
+func (decimal Decimal) String() string {
+	return string(decimal)
+}
+
+func (decimal *Decimal) UnmarshalJSON(data []byte) error {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	switch vv := value.(type) {
+	case json.Number:
+		return decimal.Scan(vv.String())
+	case string:
+		return decimal.Scan(vv)
+	}
+	return fmt.Errorf("trance: invalid JSON decimal '%s'", data)
+}
+
+// Value returns an error for empty and invalid decimals, rather than writing them as zero.
+func (decimal Decimal) Value() (driver.Value, error) {
+	if _, err := ParseDecimal(string(decimal)); err != nil {
+		return nil, err
+	}
+	return string(decimal), nil
+}
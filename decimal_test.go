@@ -0,0 +1,94 @@
+package trance
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalScan(t *testing.T) {
+	values := map[Decimal]any{
+		"12.50":                    []byte("12.50"),
+		"-0.000000000000000000001": "-0.000000000000000000001",
+		"42":                       int64(42),
+		"1.25":                     float64(1.25),
+		"":                         nil,
+	}
+	for expected, src := range values {
+		var actual Decimal
+		if err := actual.Scan(src); err != nil {
+			t.Fatalf("Unexpected error for '%#v': %s", src, err)
+		}
+		if actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	}
+
+	for _, invalid := range []any{"abc", "1e5", "1.2.3", true} {
+		var actual Decimal
+		if err := actual.Scan(invalid); err == nil {
+			t.Errorf("Expected error for '%#v'", invalid)
+		}
+	}
+}
+
+func TestDecimalValue(t *testing.T) {
+	if value, err := Decimal("12.50").Value(); err != nil || value != "12.50" {
+		t.Errorf("Expected '12.50', got '%#v' with error %v", value, err)
+	}
+	for _, invalid := range []Decimal{"", "abc", "1e5"} {
+		if _, err := invalid.Value(); err == nil {
+			t.Errorf("Expected error for '%s'", invalid)
+		}
+	}
+}
+
+func TestDecimalJson(t *testing.T) {
+	var actual struct {
+		Amount Decimal `json:"amount"`
+		Total  Decimal `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"19.99","total":1234567890.123456789}`), &actual); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if actual.Amount != "19.99" || actual.Total != "1234567890.123456789" {
+		t.Errorf("Unexpected '%+v'", actual)
+	}
+
+	encoded, err := json.Marshal(actual)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := `{"amount":"19.99","total":"1234567890.123456789"}`; string(encoded) != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, encoded)
+	}
+}
+
+func TestDecimalRat(t *testing.T) {
+	a, _ := Decimal("0.10").Rat()
+	b, _ := Decimal("0.20").Rat()
+	if actual := DecimalFromRat(new(big.Rat).Add(a, b), 2); actual != "0.30" {
+		t.Errorf("Expected '0.30', got '%s'", actual)
+	}
+}
+
+func TestModelDecimalFields(t *testing.T) {
+	type testDecimalModel struct {
+		Amount Decimal `@:"amount" @precision:"12" @scale:"2"`
+		Id     int64   `@:"id" @primary:"true"`
+	}
+	defer PurgeWeaves()
+	weave := UseWith[testDecimalModel](WeaveConfig{NoCache: true})
+
+	row, err := weave.ScanMap(map[string]any{"amount": []byte("10.05"), "id": int64(1)})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Amount != "10.05" {
+		t.Errorf("Expected '10.05', got '%s'", row.Amount)
+	}
+	if _, err := weave.ScanMap(map[string]any{"amount": "ten"}); err == nil {
+		t.Error("Expected error for invalid decimal")
+	}
+	assertMapDeepEquals(t, weave.ToJsonMap(row), map[string]any{"amount": "10.05", "id": int64(1)})
+}
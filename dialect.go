@@ -1,17 +1,26 @@
 package trance
 
 import (
+	"context"
+	"database/sql"
 	"reflect"
 )
 
 type Dialect interface {
 	BuildDelete(QueryConfig) (string, []any, error)
+	BuildIndexCreate(QueryConfig, Index, IndexCreateConfig) (string, error)
+	BuildIndexDrop(QueryConfig, string, IndexDropConfig) (string, error)
 	BuildInsert(QueryConfig, map[string]any, ...string) (string, []any, error)
 	BuildSelect(QueryConfig) (string, []any, error)
 	BuildTableColumnAdd(QueryConfig, string) (string, error)
+	BuildTableColumnAlter(QueryConfig, string) (string, error)
 	BuildTableColumnDrop(QueryConfig, string) (string, error)
+	BuildTableColumnRename(QueryConfig, string, string) (string, error)
 	BuildTableCreate(QueryConfig, TableCreateConfig) (string, error)
 	BuildTableDrop(QueryConfig, TableDropConfig) (string, error)
+	BuildTableForeignKeyAdd(QueryConfig, string) (string, error)
+	BuildTableForeignKeyDrop(QueryConfig, string) (string, error)
+	BuildTableRename(QueryConfig, string) (string, error)
 	BuildUpdate(QueryConfig, map[string]any, ...string) (string, []any, error)
 	ColumnType(reflect.StructField) (string, error)
 	Param(i int) string
@@ -26,6 +35,21 @@ type DialectStringerWithArgs interface {
 	StringWithArgs(Dialect, []any) (string, []any, error)
 }
 
+// SequenceResetter is implemented by dialects whose auto-incrementing primary keys draw from sequences that rows
+// inserted with explicit primary keys do not advance, such as Postgres. BuildSequenceReset returns a statement that
+// advances the sequence past the largest primary key of the table, or an empty string when there is no sequence.
+type SequenceResetter interface {
+	BuildSequenceReset(QueryConfig) (string, error)
+}
+
+// TableRebuilder is implemented by dialects that apply some alterations by recreating the table, such as SQLite.
+// Dropping the old table applies the ON DELETE actions of foreign keys referencing it, so the dialect runs the
+// statements from BuildTableColumnAlter, BuildTableForeignKeyAdd, and BuildTableForeignKeyDrop itself. They run inside
+// config.Transaction when it is set.
+type TableRebuilder interface {
+	ExecTableRebuild(ctx context.Context, db *sql.DB, config QueryConfig, statements string) (sql.Result, error)
+}
+
 var defaultDialect Dialect
 
 func GetDialect() Dialect {
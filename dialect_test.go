@@ -12,6 +12,14 @@ func (dialect testDialect) BuildDelete(QueryConfig) (string, []any, error) {
 	panic("Not implemented")
 }
 
+func (dialect testDialect) BuildIndexCreate(QueryConfig, Index, IndexCreateConfig) (string, error) {
+	panic("Not implemented")
+}
+
+func (dialect testDialect) BuildIndexDrop(QueryConfig, string, IndexDropConfig) (string, error) {
+	panic("Not implemented")
+}
+
 func (dialect testDialect) BuildInsert(QueryConfig, map[string]any, ...string) (string, []any, error) {
 	panic("Not implemented")
 }
@@ -24,10 +32,18 @@ func (dialect testDialect) BuildTableColumnAdd(QueryConfig, string) (string, err
 	panic("Not implemented")
 }
 
+func (dialect testDialect) BuildTableColumnAlter(config QueryConfig, column string) (string, error) {
+	return fmt.Sprintf("ALTER|%s|%s|", config.Table, column), nil
+}
+
 func (dialect testDialect) BuildTableColumnDrop(QueryConfig, string) (string, error) {
 	panic("Not implemented")
 }
 
+func (dialect testDialect) BuildTableColumnRename(QueryConfig, string, string) (string, error) {
+	panic("Not implemented")
+}
+
 func (dialect testDialect) BuildTableCreate(QueryConfig, TableCreateConfig) (string, error) {
 	panic("Not implemented")
 }
@@ -36,6 +52,18 @@ func (dialect testDialect) BuildTableDrop(QueryConfig, TableDropConfig) (string,
 	panic("Not implemented")
 }
 
+func (dialect testDialect) BuildTableForeignKeyAdd(QueryConfig, string) (string, error) {
+	panic("Not implemented")
+}
+
+func (dialect testDialect) BuildTableForeignKeyDrop(QueryConfig, string) (string, error) {
+	panic("Not implemented")
+}
+
+func (dialect testDialect) BuildTableRename(QueryConfig, string) (string, error) {
+	panic("Not implemented")
+}
+
 func (dialect testDialect) BuildUpdate(QueryConfig, map[string]any, ...string) (string, []any, error) {
 	panic("Not implemented")
 }
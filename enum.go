@@ -0,0 +1,73 @@
+package trance
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Enum is implemented by string types that only allow a fixed set of values. Enum fields map to a Postgres ENUM type,
+// a MySQL ENUM column, and a CHECK constraint on SQLite.
+type Enum interface {
+	Values() []string
+}
+
+var enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+// EnumValues returns the allowed values of enum fields, including pointer and sql.Null[T] enum fields.
+func EnumValues(fieldType reflect.Type) ([]string, bool) {
+	if nullableType, ok := NullableType(fieldType); ok {
+		fieldType = nullableType
+	}
+	if fieldType.Kind() != reflect.String || !fieldType.Implements(enumType) {
+		return nil, false
+	}
+	return reflect.Zero(fieldType).Interface().(Enum).Values(), true
+}
+
+// EnumTypeName returns the name of the Postgres type for an enum field. It defaults to the lowercased name of the Go
+// type, and can be set with the `@enum` field tag.
+func EnumTypeName(field reflect.StructField) string {
+	if tagEnum := field.Tag.Get("@enum"); tagEnum != "" {
+		return tagEnum
+	}
+	fieldType := field.Type
+	if nullableType, ok := NullableType(fieldType); ok {
+		fieldType = nullableType
+	}
+	return strings.ToLower(fieldType.Name())
+}
+
+// enumString returns the string value of an enum field. It returns false for NULL values.
+func enumString(value reflect.Value) (string, bool) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		// sql.Null[T].
+		if !value.FieldByName("Valid").Bool() {
+			return "", false
+		}
+		value = value.FieldByName("V")
+	}
+	return value.String(), true
+}
+
+func (weave *Weave[T]) validateEnums(record *T, errorsMap map[string]error) {
+	value := reflect.ValueOf(record).Elem()
+	for column, descriptor := range weave.Descriptors {
+		if descriptor.EnumValues == nil {
+			continue
+		}
+		if _, exists := errorsMap[column]; exists {
+			continue
+		}
+		if enumValue, ok := enumString(fieldByIndex(value, descriptor.Index, false)); ok && enumValue != "" && !slices.Contains(descriptor.EnumValues, enumValue) {
+			errorsMap[column] = errors.New("Select a valid choice.")
+		}
+	}
+}
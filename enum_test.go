@@ -0,0 +1,117 @@
+package trance
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+type testStatus string
+
+func (status testStatus) Values() []string {
+	return []string{"active", "archived"}
+}
+
+type testEnumModel struct {
+	Id       int64       `@:"id" @primary:"true"`
+	Previous *testStatus `@:"previous"`
+	Status   testStatus  `@:"status"`
+}
+
+func TestEnumValues(t *testing.T) {
+	type testModel struct {
+		Nullable *testStatus `@:"nullable" @enum:"status_type"`
+		Status   testStatus  `@:"status"`
+		Text     string      `@:"text"`
+	}
+	modelType := reflect.TypeOf(testModel{})
+
+	for _, name := range []string{"Nullable", "Status"} {
+		field, _ := modelType.FieldByName(name)
+		values, ok := EnumValues(field.Type)
+		if !ok || !slices.Equal(values, []string{"active", "archived"}) {
+			t.Errorf("Expected enum values for '%s', got '%#v'", name, values)
+		}
+	}
+	field, _ := modelType.FieldByName("Text")
+	if _, ok := EnumValues(field.Type); ok {
+		t.Error("Expected string field not to be an enum")
+	}
+
+	field, _ = modelType.FieldByName("Status")
+	if name := EnumTypeName(field); name != "teststatus" {
+		t.Errorf("Expected 'teststatus', got '%s'", name)
+	}
+	field, _ = modelType.FieldByName("Nullable")
+	if name := EnumTypeName(field); name != "status_type" {
+		t.Errorf("Expected 'status_type', got '%s'", name)
+	}
+}
+
+func TestEnumValidate(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testEnumModel](WeaveConfig{NoCache: true}).Clone()
+	delete(weave.Descriptors, "id")
+	delete(weave.Fields, "id")
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("status=active"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	row, err := weave.Validate(request)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Status != "active" || row.Previous != nil {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("status=deleted&previous=unknown"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err = weave.Validate(request)
+	formErrors, ok := err.(FormErrors)
+	if !ok {
+		t.Fatalf("Expected FormErrors, got '%#v'", err)
+	}
+	if len(formErrors.Errors) != 2 || formErrors.Errors["status"] == nil || formErrors.Errors["previous"] == nil {
+		t.Errorf("Expected errors for 'status' and 'previous', got '%#v'", formErrors.Errors)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"status":"deleted"}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err = weave.Validate(request)
+	formErrors, ok = err.(FormErrors)
+	if !ok {
+		t.Fatalf("Expected FormErrors, got '%#v'", err)
+	}
+	if len(formErrors.Errors) != 1 || formErrors.Errors["status"] == nil {
+		t.Errorf("Expected an error for 'status', got '%#v'", formErrors.Errors)
+	}
+}
+
+func TestEnumFormComponent(t *testing.T) {
+	defer PurgeWeaves()
+	form := Form[testEnumModel]{
+		Fields: []string{"status"},
+		Value:  &testEnumModel{Status: "archived"},
+		Weave:  UseWith[testEnumModel](WeaveConfig{NoCache: true}),
+	}
+
+	var html bytes.Buffer
+	if err := form.Component().Render(context.Background(), &html); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, expected := range []string{
+		`<select name="status">`,
+		`<option value="active">active</option>`,
+		`<option value="archived" selected>archived</option>`,
+	} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, html.String())
+		}
+	}
+}
@@ -61,6 +61,36 @@ func (err ErrorNotFound) Status() int {
 	return http.StatusNotFound
 }
 
+// ErrorPanic is returned by RecoverPanics for a handler that panicked. The value and stack are kept for logging, and
+// are not included in the error message.
+type ErrorPanic struct {
+	Stack []byte
+	Value any
+}
+
+func (err ErrorPanic) Error() string {
+	return "Internal server error"
+}
+
+func (err ErrorPanic) Status() int {
+	return http.StatusInternalServerError
+}
+
+type ErrorServiceUnavailable struct {
+	Message string
+}
+
+func (err ErrorServiceUnavailable) Error() string {
+	if err.Message != "" {
+		return err.Message
+	}
+	return "Service unavailable"
+}
+
+func (err ErrorServiceUnavailable) Status() int {
+	return http.StatusServiceUnavailable
+}
+
 type ErrorUnauthorized struct{}
 
 func (err ErrorUnauthorized) Error() string {
@@ -0,0 +1,201 @@
+package trance
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FieldKind int
+
+const (
+	// FieldKindValue fields are assigned and written as-is.
+	FieldKindValue FieldKind = iota
+	// FieldKindValuer fields are structs implementing driver.Valuer, such as sql.NullString.
+	FieldKindValuer
+	FieldKindForeignKey
+	FieldKindNullForeignKey
+	FieldKindOneToMany
+	// FieldKindJson fields have a `@json:"true"` tag and are marshaled to JSON on write and unmarshaled on scan.
+	FieldKindJson
+	// FieldKindArray fields are slices other than []byte, which are written and scanned as Postgres arrays.
+	FieldKindArray
+	// FieldKindRegistered fields have a type added with RegisterType.
+	FieldKindRegistered
+	// FieldKindUnsupported fields are structs that cannot be written to a column.
+	FieldKindUnsupported
+)
+
+type FieldDescriptor struct {
+	Column              string
+	EnumValues          []string
+	Field               reflect.StructField
+	Index               []int
+	JsonKey             string
+	Kind                FieldKind
+	Nullable            bool
+	Primary             bool
+	RelatedPrimaryIndex []int
+	RelatedType         reflect.Type
+	ScanType            reflect.Type
+	registered          *registeredType
+}
+
+var (
+	anyType          = reflect.TypeOf((*any)(nil)).Elem()
+	decimalType      = reflect.TypeOf(Decimal(""))
+	driverValuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	nullTimeType     = reflect.TypeOf(sql.NullTime{})
+	primaryIndexes   = &sync.Map{}
+	timeType         = reflect.TypeOf(time.Time{})
+)
+
+func newFieldDescriptor(field reflect.StructField, column string) *FieldDescriptor {
+	descriptor := &FieldDescriptor{
+		Column:   column,
+		Field:    field,
+		Index:    field.Index,
+		JsonKey:  strings.ToLower(field.Name),
+		Kind:     FieldKindValue,
+		Nullable: isNullableField(field.Type),
+		Primary:  field.Tag.Get("@primary") == "true",
+		ScanType: field.Type,
+	}
+	descriptor.EnumValues, _ = EnumValues(field.Type)
+
+	if registered := lookupRegisteredType(field.Type); registered != nil {
+		descriptor.Kind = FieldKindRegistered
+		descriptor.ScanType = anyType
+		descriptor.registered = registered
+	} else if field.Tag.Get("@json") == "true" && !field.Type.Implements(jsonColumnerType) {
+		descriptor.Kind = FieldKindJson
+		descriptor.ScanType = reflect.TypeOf([]byte(nil))
+	} else if isArrayType(field.Type) {
+		descriptor.Kind = FieldKindArray
+		descriptor.ScanType = reflect.TypeOf([]byte(nil))
+	} else if reflect.PointerTo(field.Type).Implements(relationFieldType) {
+		switch reflect.New(field.Type).Interface().(relationField).relationKind() {
+		case RelationForeignKey:
+			descriptor.Kind = FieldKindForeignKey
+		case RelationNullForeignKey:
+			descriptor.Kind = FieldKindNullForeignKey
+		case RelationOneToMany:
+			descriptor.Kind = FieldKindOneToMany
+		}
+	} else if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+		if field.Type.Implements(driverValuerType) {
+			descriptor.Kind = FieldKindValuer
+		} else {
+			descriptor.Kind = FieldKindUnsupported
+		}
+	}
+
+	if descriptor.Kind == FieldKindForeignKey || descriptor.Kind == FieldKindNullForeignKey {
+		// ForeignKey and NullForeignKey both store the related model in a `Row *To` field.
+		rowField, _ := field.Type.FieldByName("Row")
+		descriptor.RelatedType = rowField.Type.Elem()
+		descriptor.RelatedPrimaryIndex = primaryIndex(descriptor.RelatedType)
+		if descriptor.RelatedPrimaryIndex != nil {
+			descriptor.ScanType = descriptor.RelatedType.FieldByIndex(descriptor.RelatedPrimaryIndex).Type
+			if descriptor.Kind == FieldKindNullForeignKey {
+				// Nullable references scan into a pointer, which is nil for NULL.
+				descriptor.ScanType = reflect.PointerTo(descriptor.ScanType)
+			}
+		}
+	}
+
+	return descriptor
+}
+
+// NullableType returns the underlying type of pointer and sql.Null[T] fields, which are stored as nullable columns.
+func NullableType(fieldType reflect.Type) (reflect.Type, bool) {
+	if fieldType.Kind() == reflect.Pointer {
+		return fieldType.Elem(), true
+	}
+	if fieldType.Kind() == reflect.Struct && fieldType.PkgPath() == "database/sql" && strings.HasPrefix(fieldType.Name(), "Null[") {
+		valueField, _ := fieldType.FieldByName("V")
+		return valueField.Type, true
+	}
+	return fieldType, false
+}
+
+// isNullableField reports whether a field may be left out of forms, which is the case for pointer and sql.Null[T] fields.
+// Named database/sql null types, such as sql.NullString, are still required by form validation.
+func isNullableField(fieldType reflect.Type) bool {
+	_, ok := NullableType(fieldType)
+	return ok
+}
+
+// fieldByIndex returns the nested field at index. Nil pointers to embedded or nested structs are allocated when alloc
+// is true. Otherwise a zero value is returned in place of the field.
+func fieldByIndex(value reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !alloc {
+					return reflect.New(value.Type().Elem().FieldByIndex(index[i:]).Type).Elem()
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+// primaryIndex returns the index path of the `@primary:"true"` field on a model type, or nil if there is none.
+func primaryIndex(modelType reflect.Type) []int {
+	if existing, ok := primaryIndexes.Load(modelType); ok {
+		return existing.([]int)
+	}
+	var index []int
+	walkFields(modelType, func(field reflect.StructField, _ string) {
+		if index == nil && field.Tag.Get("@primary") == "true" {
+			index = field.Index
+		}
+	})
+	primaryIndexes.Store(modelType, index)
+	return index
+}
+
+// walkFields visits every field with a `@` column tag, including fields promoted from embedded structs. Named struct
+// fields with a `@prefix` tag are walked recursively, prepending the prefix to their columns. Visited fields have an
+// index path relative to modelType, and nested fields are named by their path, such as "Address.City".
+func walkFields(modelType reflect.Type, visit func(field reflect.StructField, column string)) {
+	walkFieldsWithPrefix(modelType, "", reflect.StructField{}, visit)
+}
+
+func walkFieldsWithPrefix(modelType reflect.Type, prefix string, parent reflect.StructField, visit func(reflect.StructField, string)) {
+	nested := make([][]int, 0)
+	for _, field := range reflect.VisibleFields(modelType) {
+		if slices.ContainsFunc(nested, func(index []int) bool {
+			return len(field.Index) > len(index) && slices.Equal(field.Index[:len(index)], index)
+		}) {
+			// Promoted from a prefixed struct, which is walked separately.
+			continue
+		}
+
+		if parent.Index != nil {
+			field.Name = parent.Name + "." + field.Name
+			field.Index = append(slices.Clone(parent.Index), field.Index...)
+		}
+
+		if column, ok := field.Tag.Lookup("@"); ok {
+			visit(field, prefix+column)
+		} else if fieldPrefix, ok := field.Tag.Lookup("@prefix"); ok {
+			nested = append(nested, field.Index[len(parent.Index):])
+			nestedType := field.Type
+			if nestedType.Kind() == reflect.Pointer {
+				nestedType = nestedType.Elem()
+			}
+			if nestedType.Kind() == reflect.Struct {
+				walkFieldsWithPrefix(nestedType, prefix+fieldPrefix, field, visit)
+			}
+		}
+	}
+}
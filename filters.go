@@ -1,7 +1,10 @@
 package trance
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -20,8 +23,10 @@ var filterOperators = map[string]struct{}{
 	"IS":         {},
 	"IS NOT":     {},
 	"ALL":        {},
+	"= ALL":      {},
 	"<> ALL":     {},
 	"ANY":        {},
+	"= ANY":      {},
 	"<> ANY":     {},
 	"EXISTS":     {},
 	"NOT EXISTS": {},
@@ -40,6 +45,48 @@ type FilterClause struct {
 	Rule     string
 }
 
+func (filter FilterClause) isArrayOperator() bool {
+	switch filter.Operator {
+	case "ALL", "= ALL", "<> ALL", "ANY", "= ANY", "<> ANY":
+		return true
+	}
+	return false
+}
+
+func (filter FilterClause) isContainmentOperator() bool {
+	return filter.Operator == "@>" || filter.Operator == "<@"
+}
+
+// jsonContainmentFilters returns filters with the right side of containment filters on JSON fields wrapped as JSON so
+// that they are sent as encoded documents. The original slice is not modified.
+func jsonContainmentFilters(filters []FilterClause, fields map[string]reflect.StructField) []FilterClause {
+	var wrapped []FilterClause
+	for i, filter := range filters {
+		if !filter.isContainmentOperator() {
+			continue
+		}
+		column, ok := filter.Left.(string)
+		if !ok {
+			continue
+		}
+		if field, ok := fields[column]; !ok || !IsJsonField(field) {
+			continue
+		}
+		switch filter.Right.(type) {
+		case nil, string, []byte, driver.Valuer, DialectStringer, DialectStringerWithArgs, SqlUnsafe:
+			continue
+		}
+		if wrapped == nil {
+			wrapped = slices.Clone(filters)
+		}
+		wrapped[i].Right = JSON[any]{Data: filter.Right}
+	}
+	if wrapped == nil {
+		return filters
+	}
+	return wrapped
+}
+
 func (filter FilterClause) leftString(dialect Dialect, args []any) ([]any, string, error) {
 	switch left := filter.Left.(type) {
 	case string:
@@ -85,7 +132,16 @@ func (filter FilterClause) rightString(dialect Dialect, args []any) ([]any, stri
 		}
 		return args, sliceArgs.String(), nil
 
+	case string, []byte, driver.Valuer:
+		args = append(args, right)
+		return args, dialect.Param(len(args)), nil
+
 	default:
+		if (filter.isArrayOperator() || filter.isContainmentOperator()) && isArrayType(reflect.TypeOf(right)) {
+			// Typed slices are passed to ANY, ALL, and array containment as a single array parameter.
+			args = append(args, Array(right))
+			return args, dialect.Param(len(args)), nil
+		}
 		args = append(args, right)
 		return args, dialect.Param(len(args)), nil
 	}
@@ -128,7 +184,7 @@ func (filter FilterClause) StringWithArgs(dialect Dialect, args []any) (string,
 
 		if filter.Operator == "EXISTS" || filter.Operator == "NOT EXISTS" {
 			return fmt.Sprintf(" %s (%s)", filter.Operator, right), args, nil
-		} else if filter.Operator == "IN" || filter.Operator == "NOT IN" || filter.Operator == "ALL" || filter.Operator == "<> ALL" || filter.Operator == "ANY" || filter.Operator == "<> ANY" {
+		} else if filter.Operator == "IN" || filter.Operator == "NOT IN" || filter.isArrayOperator() {
 			return fmt.Sprintf(" %s %s (%s)", left, filter.Operator, right), args, nil
 		} else if filter.Operator == "?&" || filter.Operator == "?|" {
 			switch filter.Right.(type) {
@@ -0,0 +1,277 @@
+package trance
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures are rows keyed by table name. Rows are keyed by column or JSON key, and foreign keys hold the primary key of
+// the related row.
+type Fixtures map[string][]map[string]any
+
+var fixtureDecoders = map[string]func([]byte, any) error{
+	".json": decodeJsonFixtures,
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+}
+var fixtureDecodersMutex sync.RWMutex
+
+// RegisterFixtureDecoder adds or replaces a decoder for fixture files with an extension, such as ".toml". Decoders
+// unmarshal into *Fixtures. JSON (".json") and YAML (".yaml" and ".yml") are supported by default.
+func RegisterFixtureDecoder(extension string, decode func([]byte, any) error) {
+	fixtureDecodersMutex.Lock()
+	defer fixtureDecodersMutex.Unlock()
+	fixtureDecoders[strings.ToLower(extension)] = decode
+}
+
+func decodeJsonFixtures(data []byte, fixtures any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(fixtures)
+}
+
+// DumpFixtures returns rows as fixtures that LoadFixtures can read back. Values come from Weave.ToJsonMap, with
+// foreign keys replaced by related primary keys and OneToMany fields left out.
+func DumpFixtures[T any](rows []*T) Fixtures {
+	weave := Use[T]()
+	dumped := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		jsonMap := weave.ToJsonMap(row)
+		value := reflect.ValueOf(row).Elem()
+		for _, descriptor := range weave.Descriptors {
+			switch descriptor.Kind {
+			case FieldKindOneToMany:
+				delete(jsonMap, descriptor.JsonKey)
+
+			case FieldKindForeignKey, FieldKindNullForeignKey:
+				field := fieldByIndex(value, descriptor.Index, false)
+				if key, ok := field.Addr().Interface().(relationField).relatedKey(); ok {
+					jsonMap[descriptor.JsonKey] = key
+				} else {
+					jsonMap[descriptor.JsonKey] = nil
+				}
+			}
+		}
+		dumped = append(dumped, jsonMap)
+	}
+	return Fixtures{weave.Table: dumped}
+}
+
+// DumpRegisteredFixtures returns the rows of tables belonging to models added with RegisterModel, ordered by primary
+// key. All registered models are dumped when no tables are given.
+func DumpRegisteredFixtures(tables ...string) (Fixtures, error) {
+	registeredModelsMutex.Lock()
+	models := slices.Clone(registeredModels)
+	registeredModelsMutex.Unlock()
+
+	fixtures := make(Fixtures)
+	for _, model := range models {
+		if table, _ := model.columns(); len(tables) > 0 && !slices.Contains(tables, table) {
+			continue
+		}
+		dumped, err := model.dumpFixtures()
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(fixtures, dumped)
+	}
+	for _, table := range tables {
+		if _, ok := fixtures[table]; !ok {
+			return nil, fmt.Errorf("trance: no model registered for table '%s'. Register one with trance.RegisterModel", table)
+		}
+	}
+	return fixtures, nil
+}
+
+func dumpTableFixtures[T any]() (Fixtures, error) {
+	query := Query[T]()
+	if query.Weave.PrimaryColumn != "" {
+		query = query.Sort(query.Weave.PrimaryColumn)
+	}
+	rows, err := query.Collect()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("trance: failed to dump table '%s'", query.Weave.Table), err)
+	}
+	return DumpFixtures(rows), nil
+}
+
+// LoadFixtures inserts the rows of fixture files in one transaction. Tables must belong to models added with
+// RegisterModel, and are inserted in foreign key order. Rows of a table keep their order across files. Files are
+// decoded by extension. See RegisterFixtureDecoder. Primary key sequences of tables given explicit primary keys are
+// advanced when the dialect implements SequenceResetter, so that later inserts do not reuse them.
+func LoadFixtures(fsys fs.FS, paths ...string) error {
+	db := Database()
+	if db == nil {
+		return UseDatabaseError{}
+	}
+
+	fixtures := make(Fixtures)
+	for _, filePath := range paths {
+		fixtureDecodersMutex.RLock()
+		decode, ok := fixtureDecoders[strings.ToLower(path.Ext(filePath))]
+		fixtureDecodersMutex.RUnlock()
+		if !ok {
+			return fmt.Errorf("trance: no fixture decoder for '%s'. Register one with trance.RegisterFixtureDecoder", filePath)
+		}
+		data, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return errors.Join(fmt.Errorf("trance: failed to read fixtures '%s'", filePath), err)
+		}
+		var fileFixtures Fixtures
+		if err := decode(data, &fileFixtures); err != nil {
+			return errors.Join(fmt.Errorf("trance: failed to decode fixtures '%s'", filePath), err)
+		}
+		for table, rows := range fileFixtures {
+			fixtures[table] = append(fixtures[table], rows...)
+		}
+	}
+
+	registeredModelsMutex.Lock()
+	models := make([]registeredModel, 0, len(fixtures))
+	for _, model := range registeredModels {
+		table, _ := model.columns()
+		if _, ok := fixtures[table]; ok {
+			models = append(models, model)
+		}
+	}
+	registeredModelsMutex.Unlock()
+	for table := range fixtures {
+		if !slices.ContainsFunc(models, func(model registeredModel) bool { modelTable, _ := model.columns(); return modelTable == table }) {
+			return fmt.Errorf("trance: no model registered for table '%s'. Register one with trance.RegisterModel", table)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, model := range sortModelsByForeignKeys(models) {
+		table, _ := model.columns()
+		explicitPrimary := false
+		for i, row := range fixtures[table] {
+			explicit, err := model.insertFixture(tx, row)
+			if err != nil {
+				return errors.Join(fmt.Errorf("trance: failed to load fixture %d of table '%s'", i+1, table), err, tx.Rollback())
+			}
+			explicitPrimary = explicitPrimary || explicit
+		}
+		if explicitPrimary {
+			if err := model.resetSequence(tx); err != nil {
+				return errors.Join(fmt.Errorf("trance: failed to reset primary key sequence of table '%s'", table), err, tx.Rollback())
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// insertFixture inserts a fixture row using Weave.ScanMap, and reports whether the row set its primary key.
+func insertFixture[T any](tx *sql.Tx, row map[string]any) (bool, error) {
+	weave := Use[T]()
+	descriptors := make(map[string]*FieldDescriptor, len(weave.Descriptors)*2)
+	for _, descriptor := range weave.Descriptors {
+		descriptors[descriptor.Column] = descriptor
+		descriptors[descriptor.JsonKey] = descriptor
+	}
+
+	data := make(map[string]any, len(row))
+	for key, value := range row {
+		descriptor, ok := descriptors[key]
+		if !ok {
+			return false, fmt.Errorf("trance: unknown column '%s'", key)
+		}
+		if descriptor.Kind == FieldKindOneToMany {
+			continue
+		}
+		value, err := fixtureValue(descriptor, value)
+		if err != nil {
+			return false, errors.Join(fmt.Errorf("trance: invalid value for column '%s'", descriptor.Column), err)
+		}
+		data[descriptor.Column] = value
+	}
+
+	record, err := weave.ScanMap(data)
+	if err != nil {
+		return false, err
+	}
+	explicitPrimary := weave.PrimaryColumn != "" && data[weave.PrimaryColumn] != nil
+	return explicitPrimary, Query[T]().Transaction(tx).Insert(record).Error
+}
+
+// resetFixtureSequence advances the primary key sequence of a table past fixtures inserted with explicit primary keys,
+// when the dialect implements SequenceResetter.
+func resetFixtureSequence[T any](tx *sql.Tx) error {
+	query := Query[T]().Transaction(tx)
+	query.detectDialect()
+	resetter, ok := query.dialect.(SequenceResetter)
+	if !ok {
+		return nil
+	}
+	query.configure()
+	queryString, err := resetter.BuildSequenceReset(query.Config)
+	if err != nil || queryString == "" {
+		return err
+	}
+	_, err = tx.Exec(queryString)
+	return err
+}
+
+// fixtureValue converts a decoded value to a value Weave.ScanMap accepts for a field.
+func fixtureValue(descriptor *FieldDescriptor, value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+
+	case json.Number:
+		if fieldType, _ := NullableType(descriptor.Field.Type); fieldType == decimalType {
+			// Decimals keep their digits rather than passing through float64.
+			return v.String(), nil
+		}
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+
+	case string:
+		if fieldType, _ := NullableType(descriptor.Field.Type); fieldType == timeType || fieldType == nullTimeType {
+			return time.Parse(time.RFC3339Nano, v)
+		}
+
+	case map[string]any:
+		if descriptor.Kind == FieldKindForeignKey || descriptor.Kind == FieldKindNullForeignKey {
+			// Related rows, such as {"id": 1}, are reduced to their primary key.
+			if descriptor.RelatedPrimaryIndex == nil {
+				return nil, fmt.Errorf("trance: model '%s' has no primary key", descriptor.RelatedType)
+			}
+			return fixtureValue(descriptor, v[strings.ToLower(descriptor.RelatedType.FieldByIndex(descriptor.RelatedPrimaryIndex).Name)])
+		}
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice:
+		// JSON and array fields are decoded into the type of the field.
+		fieldType, _ := NullableType(descriptor.Field.Type)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		decoded := reflect.New(fieldType)
+		if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+			return nil, err
+		}
+		return decoded.Elem().Interface(), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,240 @@
+package trance
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+type testFixtureDialect struct {
+	testDialect
+}
+
+func (dialect testFixtureDialect) BuildInsert(config QueryConfig, data map[string]any, columns ...string) (string, []any, error) {
+	slices.Sort(columns)
+	args := make([]any, 0, len(columns))
+	for _, column := range columns {
+		args = append(args, data[column])
+	}
+	return fmt.Sprintf("INSERT|%s|%s|", config.Table, strings.Join(columns, ",")), args, nil
+}
+
+func (dialect testFixtureDialect) BuildSequenceReset(config QueryConfig) (string, error) {
+	return fmt.Sprintf("SEQUENCE|%s|", config.Table), nil
+}
+
+type testFixtureGroup struct {
+	Accounts OneToMany[testFixtureAccount] `@:"group_id"`
+	Id       int64                         `@:"id" @primary:"true"`
+	Name     string                        `@:"name"`
+}
+
+type testFixtureAccount struct {
+	CreatedAt time.Time                    `@:"created_at"`
+	Group     ForeignKey[testFixtureGroup] `@:"group_id"`
+	Id        int64                        `@:"id" @primary:"true"`
+	Nickname  *string                      `@:"nickname"`
+	Tags      []string                     `@:"tags" @json:"true"`
+}
+
+func TestLoadFixtures(t *testing.T) {
+	registered := registeredModels
+	defer func() {
+		defaultDialect = nil
+		registeredModels = registered
+		PurgeWeaves()
+	}()
+	registeredModels = nil
+	RegisterModel[testFixtureAccount]()
+	RegisterModel[testFixtureGroup]()
+	SetDialect(testFixtureDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	fsys := fstest.MapFS{
+		"accounts.json": {Data: []byte(`{"testfixtureaccount": [
+			{"id": 10, "group_id": 1, "created_at": "2024-01-02T03:04:05Z", "tags": ["a", "b"]},
+			{"id": 11, "group": {"id": 2}, "createdat": "2024-01-02T03:04:05Z", "nickname": "x", "tags": null}
+		]}`)},
+		"groups.json":           {Data: []byte(`{"testfixturegroup": [{"id": 1, "name": "One"}, {"id": 2, "name": "Two"}]}`)},
+		"groups.toml":           {Data: []byte(`testfixturegroup = []`)},
+		"groups_generated.json": {Data: []byte(`{"testfixturegroup": [{"name": "Four"}]}`)},
+		"groups.yaml":           {Data: []byte("testfixturegroup:\n  - id: 3\n    name: Three\n")},
+		"unknown.json":          {Data: []byte(`{"testfixturegroup": [{"id": 3, "title": "Three"}]}`)},
+	}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT|testfixturegroup|id,name|").WithArgs(int64(1), "One").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT|testfixturegroup|id,name|").WithArgs(int64(2), "Two").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("SEQUENCE|testfixturegroup|").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT|testfixtureaccount|created_at,group_id,id,nickname,tags|").WithArgs(createdAt, int64(1), int64(10), nil, `["a","b"]`).WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectExec("INSERT|testfixtureaccount|created_at,group_id,id,nickname,tags|").WithArgs(createdAt, int64(2), int64(11), "x", nil).WillReturnResult(sqlmock.NewResult(11, 1))
+	mock.ExpectExec("SEQUENCE|testfixtureaccount|").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := LoadFixtures(fsys, "accounts.json", "groups.json"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	if err := LoadFixtures(fsys, "unknown.json"); err == nil {
+		t.Error("Expected error")
+	}
+	if err := LoadFixtures(fsys, "groups.toml"); err == nil {
+		t.Error("Expected error")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT|testfixturegroup|id,name|").WithArgs(int64(3), "Three").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("SEQUENCE|testfixturegroup|").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := LoadFixtures(fsys, "groups.yaml"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	// Sequences are left alone when no fixture sets the primary key.
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT|testfixturegroup|name|").WithArgs("Four").WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectCommit()
+	if err := LoadFixtures(fsys, "groups_generated.json"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	RegisterFixtureDecoder(".toml", func(data []byte, fixtures any) error {
+		*fixtures.(*Fixtures) = Fixtures{strings.TrimSpace(string(data[:strings.Index(string(data), "=")])): {}}
+		return nil
+	})
+	defer delete(fixtureDecoders, ".toml")
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	if err := LoadFixtures(fsys, "groups.toml"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDumpFixtures(t *testing.T) {
+	defer PurgeWeaves()
+	nickname := "x"
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []*testFixtureAccount{
+		{CreatedAt: createdAt, Group: ForeignKey[testFixtureGroup]{Row: &testFixtureGroup{Id: 1}, Valid: true}, Id: 10, Tags: []string{"a"}},
+		{CreatedAt: createdAt, Id: 11, Nickname: &nickname},
+	}
+	fixtures := DumpFixtures(rows)
+	if keys := maps.Keys(fixtures); !slices.Equal(keys, []string{"testfixtureaccount"}) {
+		t.Fatalf("Unexpected tables %v", keys)
+	}
+	data, err := json.Marshal(fixtures)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `{"testfixtureaccount":[` +
+		`{"createdat":"2024-01-02T03:04:05Z","group":1,"id":10,"nickname":null,"tags":["a"]},` +
+		`{"createdat":"2024-01-02T03:04:05Z","group":null,"id":11,"nickname":"x","tags":null}]}`
+	if string(data) != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, data)
+	}
+
+	var decoded Fixtures
+	if err := decodeJsonFixtures(data, &decoded); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	weave := Use[testFixtureAccount]()
+	for i, row := range decoded["testfixtureaccount"] {
+		scanData := make(map[string]any)
+		for key, value := range row {
+			for _, descriptor := range weave.Descriptors {
+				if descriptor.JsonKey == key {
+					if scanData[descriptor.Column], err = fixtureValue(descriptor, value); err != nil {
+						t.Fatal("Unexpected error:", err)
+					}
+				}
+			}
+		}
+		record, err := weave.ScanMap(scanData)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if record.Id != rows[i].Id || !record.CreatedAt.Equal(createdAt) || record.Group.Valid != rows[i].Group.Valid || !reflect.DeepEqual(record.Nickname, rows[i].Nickname) || !slices.Equal(record.Tags, rows[i].Tags) {
+			t.Errorf("Expected '%+v', got '%+v'", rows[i], record)
+		}
+	}
+}
+
+type testFixtureInvoice struct {
+	Amount   Decimal      `@:"amount" @precision:"10" @scale:"2"`
+	Id       int64        `@:"id" @primary:"true"`
+	PaidAt   sql.NullTime `@:"paid_at"`
+	SentAt   *time.Time   `@:"sent_at"`
+	Discount *Decimal     `@:"discount" @precision:"10" @scale:"2"`
+}
+
+func TestDumpLoadFixtures(t *testing.T) {
+	registered := registeredModels
+	defer func() {
+		defaultDialect = nil
+		registeredModels = registered
+		PurgeWeaves()
+	}()
+	registeredModels = nil
+	RegisterModel[testFixtureInvoice]()
+	SetDialect(testFixtureDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	paidAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sentAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	discount := Decimal("0.50")
+	rows := []*testFixtureInvoice{
+		{Amount: "10.10", Id: 1, PaidAt: sql.NullTime{Time: paidAt, Valid: true}, SentAt: &sentAt, Discount: &discount},
+		{Amount: "20.00", Id: 2},
+	}
+	for _, extension := range []string{".json", ".yaml"} {
+		var data []byte
+		if extension == ".json" {
+			data, err = json.Marshal(DumpFixtures(rows))
+		} else {
+			data, err = yaml.Marshal(DumpFixtures(rows))
+		}
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		fsys := fstest.MapFS{"invoices" + extension: {Data: data}}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT|testfixtureinvoice|amount,discount,id,paid_at,sent_at|").WithArgs("10.10", "0.50", int64(1), paidAt, sentAt).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT|testfixtureinvoice|amount,discount,id,paid_at,sent_at|").WithArgs("20.00", nil, int64(2), nil, nil).WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectExec("SEQUENCE|testfixtureinvoice|").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		if err := LoadFixtures(fsys, "invoices"+extension); err != nil {
+			t.Fatalf("Unexpected error loading '%s': %s\n%s", extension, err, data)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
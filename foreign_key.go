@@ -13,11 +13,23 @@ type ForeignKey[To any] struct {
 	Valid bool
 }
 
+func (fk *ForeignKey[To]) assignRelated(index any) {
+	if key, ok := fk.relatedKey(); ok {
+		if row, ok := index.(map[any]*To)[key]; ok {
+			fk.Row = row
+		}
+	}
+}
+
 func (fk *ForeignKey[To]) Fetch() (*To, error) {
 	query := Query[To]()
 	value := reflect.ValueOf(fk.Row).Elem()
-	id := value.FieldByName(query.Weave.PrimaryField).Interface()
-	return query.Filter("id", "=", id).CollectFirst()
+	id := query.Weave.primaryValue(value, false).Interface()
+	return query.Filter(query.Weave.PrimaryColumn, "=", id).CollectFirst()
+}
+
+func (fk *ForeignKey[To]) fetchRelated(config QueryConfig, dialect Dialect, _ string, keys []any) (any, error) {
+	return fetchRelatedByPrimary[To](config, dialect, keys)
 }
 
 func (fk ForeignKey[To]) JsonValue() any {
@@ -34,6 +46,27 @@ func (fk ForeignKey[To]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(fk.Weave().ToJsonMap(fk.Row))
 }
 
+func (fk *ForeignKey[To]) relatedKey() (any, bool) {
+	if !fk.Valid || fk.Row == nil {
+		return nil, false
+	}
+	return primaryKeyOf(fk.Row), true
+}
+
+func (fk *ForeignKey[To]) relationKind() RelationKind {
+	return RelationForeignKey
+}
+
+func (fk *ForeignKey[To]) setRelatedKey(_ string, key any) error {
+	row, err := rowWithPrimaryKey[To](key)
+	if err != nil {
+		return err
+	}
+	fk.Row = row
+	fk.Valid = true
+	return nil
+}
+
 func (fk ForeignKey[To]) String() string {
 	if !fk.Valid {
 		return ""
@@ -73,11 +106,23 @@ type NullForeignKey[To any] struct {
 	Valid bool
 }
 
+func (fk *NullForeignKey[To]) assignRelated(index any) {
+	if key, ok := fk.relatedKey(); ok {
+		if row, ok := index.(map[any]*To)[key]; ok {
+			fk.Row = row
+		}
+	}
+}
+
 func (fk *NullForeignKey[To]) Fetch() (*To, error) {
 	query := Query[To]()
 	value := reflect.ValueOf(fk.Row).Elem()
-	id := value.FieldByName(query.Weave.PrimaryField).Interface()
-	return query.Filter("id", "=", id).CollectFirst()
+	id := query.Weave.primaryValue(value, false).Interface()
+	return query.Filter(query.Weave.PrimaryColumn, "=", id).CollectFirst()
+}
+
+func (fk *NullForeignKey[To]) fetchRelated(config QueryConfig, dialect Dialect, _ string, keys []any) (any, error) {
+	return fetchRelatedByPrimary[To](config, dialect, keys)
 }
 
 func (fk NullForeignKey[To]) JsonValue() any {
@@ -94,6 +139,27 @@ func (fk NullForeignKey[To]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(fk.Weave().ToJsonMap(fk.Row))
 }
 
+func (fk *NullForeignKey[To]) relatedKey() (any, bool) {
+	if !fk.Valid || fk.Row == nil {
+		return nil, false
+	}
+	return primaryKeyOf(fk.Row), true
+}
+
+func (fk *NullForeignKey[To]) relationKind() RelationKind {
+	return RelationNullForeignKey
+}
+
+func (fk *NullForeignKey[To]) setRelatedKey(_ string, key any) error {
+	row, err := rowWithPrimaryKey[To](key)
+	if err != nil {
+		return err
+	}
+	fk.Row = row
+	fk.Valid = true
+	return nil
+}
+
 func (fk NullForeignKey[To]) String() string {
 	if !fk.Valid {
 		return ""
@@ -128,3 +194,32 @@ func (fk NullForeignKey[To]) WidgetOptions() ([]forms.WidgetOption, error) {
 func (fk NullForeignKey[To]) Query() *QueryStream[To] {
 	return Query[To]()
 }
+
+type foreignKeyReferencer interface {
+	referencedColumn() (string, string)
+}
+
+func (fk *ForeignKey[To]) referencedColumn() (string, string) {
+	weave := Use[To]()
+	return weave.Table, weave.PrimaryColumn
+}
+
+func (fk *NullForeignKey[To]) referencedColumn() (string, string) {
+	weave := Use[To]()
+	return weave.Table, weave.PrimaryColumn
+}
+
+// ForeignKeyName returns the name of the constraint for a foreign key column, which matches the Postgres default.
+func ForeignKeyName(table string, column string) string {
+	return table + "_" + column + "_fkey"
+}
+
+// ForeignKeyTarget returns the table and primary key column referenced by ForeignKey and NullForeignKey fields.
+func ForeignKeyTarget(field reflect.StructField) (string, string, bool) {
+	referencer, ok := reflect.New(field.Type).Interface().(foreignKeyReferencer)
+	if !ok {
+		return "", "", false
+	}
+	table, column := referencer.referencedColumn()
+	return table, column, true
+}
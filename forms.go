@@ -50,6 +50,38 @@ func (form *Form[T]) Component(additionalData ...map[string]any) templ.Component
 		}
 	}
 
+	// Key values by column so that prefixed and renamed columns line up with their fields.
+	jsonValues := form.Weave.ToJsonMap(form.Value)
+	values := make(map[string]any, len(jsonValues))
+	for column, descriptor := range form.Weave.Descriptors {
+		values[column] = jsonValues[descriptor.JsonKey]
+	}
+
+	// Registered types provide default widgets.
+	widgets := make(map[string]forms.Widgeter, len(form.Widgets))
+	for column, descriptor := range form.Weave.Descriptors {
+		if descriptor.registered != nil && descriptor.registered.widget != nil {
+			widgets[column] = descriptor.registered.widget
+		}
+	}
+	maps.Copy(widgets, form.Widgets)
+
+	// Enum fields render as a select of their values.
+	options := make(map[string][]forms.WidgetOption)
+	for column, descriptor := range form.Weave.Descriptors {
+		if descriptor.EnumValues == nil {
+			continue
+		}
+		enumOptions := make([]forms.WidgetOption, 0, len(descriptor.EnumValues)+1)
+		if descriptor.Nullable {
+			enumOptions = append(enumOptions, forms.WidgetOption{})
+		}
+		for _, enumValue := range descriptor.EnumValues {
+			enumOptions = append(enumOptions, forms.WidgetOption{Label: enumValue, Value: enumValue})
+		}
+		options[column] = enumOptions
+	}
+
 	data := forms.FormTemplateData{
 		Action:     form.Action,
 		Data:       make(map[string]any),
@@ -57,9 +89,10 @@ func (form *Form[T]) Component(additionalData ...map[string]any) templ.Component
 		Fields:     form.Fields,
 		FieldTypes: form.Weave.Fields,
 		Method:     form.Method,
-		Values:     form.Weave.ToJsonMap(form.Value),
+		Options:    options,
+		Values:     values,
 		ValuesMap:  form.Weave.ToValuesMap(form.Value),
-		Widgets:    form.Widgets,
+		Widgets:    widgets,
 	}
 	for _, n := range additionalData {
 		maps.Copy(data.Data, n)
@@ -80,6 +113,7 @@ func (form *Form[T]) Validate(request *http.Request) bool {
 	if len(form.Fields) > 0 {
 		for column := range temp.Fields {
 			if !slices.Contains(form.Fields, column) {
+				delete(temp.Descriptors, column)
 				delete(temp.Fields, column)
 			}
 		}
@@ -96,8 +130,8 @@ func (form *Form[T]) Validate(request *http.Request) bool {
 
 		for _, column := range form.Fields {
 			field := temp.Fields[column]
-			newValue := vv.FieldByName(field.Name)
-			fv.FieldByName(field.Name).Set(newValue)
+			newValue := fieldByIndex(vv, field.Index, false)
+			fieldByIndex(fv, field.Index, true).Set(newValue)
 		}
 	}
 
@@ -6,4 +6,5 @@ require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/a-h/templ v0.3.865
 	golang.org/x/exp v0.0.0-20250506013437-ce4c2cf36ca6
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,100 @@
+package trance
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group registers routes on an App under a shared prefix and middleware. Groups may be nested, in which case the
+// prefixes are joined and the middleware of outer groups runs first. Middleware is read when a request is handled, so
+// UseMiddleware also applies to routes registered earlier.
+type Group struct {
+	Middleware []func(*Strand) error
+
+	app    *App
+	parent *Group
+	prefix string
+}
+
+func (group *Group) Delete(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("DELETE", path, handler, middleware...)
+}
+
+func (group *Group) Get(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("GET", path, handler, middleware...)
+}
+
+// Group returns a router nested under this group, such as "/admin/users" for Group("/users") on an "/admin" group.
+func (group *Group) Group(prefix string, middleware ...func(*Strand) error) *Group {
+	return &Group{
+		Middleware: middleware,
+		app:        group.app,
+		parent:     group,
+		prefix:     group.prefix + strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+func (group *Group) Head(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("HEAD", path, handler, middleware...)
+}
+
+// middleware runs the middleware of outer groups and then the middleware of this group.
+func (group *Group) middleware(strand *Strand) error {
+	if group.parent != nil {
+		if err := group.parent.middleware(strand); err != nil {
+			return err
+		}
+	}
+	for _, middleware := range group.Middleware {
+		if err := middleware(strand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mount routes requests under the group prefix joined with prefix to a handler, with the joined prefix stripped from
+// the path.
+func (group *Group) Mount(prefix string, handler http.Handler, middleware ...func(*Strand) error) {
+	group.app.Mount(group.prefix+prefix, handler, group.withMiddleware(middleware)...)
+}
+
+func (group *Group) Options(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("OPTIONS", path, handler, middleware...)
+}
+
+func (group *Group) Patch(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("PATCH", path, handler, middleware...)
+}
+
+func (group *Group) Post(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("POST", path, handler, middleware...)
+}
+
+func (group *Group) Put(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.routeRequireMethod("PUT", path, handler, middleware...)
+}
+
+// Route handles a path under the group prefix. A method may be given as with App.Route, such as "GET /{id}".
+func (group *Group) Route(path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	method, path, ok := strings.Cut(path, " ")
+	if !ok {
+		method, path = "", method
+	} else {
+		method += " "
+	}
+	group.app.Route(method+group.prefix+path, handler, group.withMiddleware(middleware)...)
+}
+
+func (group *Group) routeRequireMethod(method string, path string, handler func(*Strand) error, middleware ...func(*Strand) error) {
+	group.app.routeRequireMethod(method, group.prefix+path, handler, group.withMiddleware(middleware)...)
+}
+
+func (group *Group) UseMiddleware(middleware ...func(*Strand) error) {
+	group.Middleware = append(group.Middleware, middleware...)
+}
+
+// withMiddleware prepends the group middleware to the middleware of a route.
+func (group *Group) withMiddleware(middleware []func(*Strand) error) []func(*Strand) error {
+	return append([]func(*Strand) error{group.middleware}, middleware...)
+}
@@ -0,0 +1,80 @@
+package trance
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	var calls []string
+	record := func(name string) func(*Strand) error {
+		return func(s *Strand) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	requireAdmin := func(s *Strand) error {
+		calls = append(calls, "auth")
+		if s.Request().Header.Get("Authorization") != "admin" {
+			return ErrorUnauthorized{}
+		}
+		return nil
+	}
+
+	app := &App{}
+	app.UseMiddleware(record("app"))
+	app.Get("/public", func(s *Strand) error {
+		return s.WriteHtml("Public")
+	})
+	app.Get("/route", func(s *Strand) error {
+		return s.WriteHtml("Route")
+	}, record("route"))
+
+	admin := app.Group("/admin/", requireAdmin)
+	admin.Get("/posts/{id}", func(s *Strand) error {
+		return s.WriteHtml("Post " + s.PathValue("id"))
+	})
+	users := admin.Group("/users", record("users"))
+	users.Post("", func(s *Strand) error {
+		return s.WriteHtml("Created")
+	}, record("create"))
+	users.Route("DELETE /{id}", func(s *Strand) error {
+		return errors.New("delete failed")
+	})
+	admin.UseMiddleware(record("admin"))
+
+	expected := []struct {
+		admin  bool
+		body   string
+		calls  string
+		method string
+		path   string
+		status int
+	}{
+		{body: "Public", calls: "app", method: "GET", path: "/public", status: http.StatusOK},
+		{body: "Route", calls: "app route", method: "GET", path: "/route", status: http.StatusOK},
+		{body: "Unauthorized\n", calls: "app auth", method: "GET", path: "/admin/posts/1", status: http.StatusUnauthorized},
+		{admin: true, body: "Post 1", calls: "app auth admin", method: "GET", path: "/admin/posts/1", status: http.StatusOK},
+		{admin: true, body: "Created", calls: "app auth admin users create", method: "POST", path: "/admin/users", status: http.StatusOK},
+		{admin: true, body: "delete failed\n", calls: "app auth admin users", method: "DELETE", path: "/admin/users/2", status: http.StatusInternalServerError},
+		{admin: true, body: "Method not allowed. Must be POST\n", calls: "app", method: "GET", path: "/admin/users", status: http.StatusMethodNotAllowed},
+	}
+	for _, e := range expected {
+		calls = nil
+		request := httptest.NewRequest(e.method, e.path, nil)
+		if e.admin {
+			request.Header.Set("Authorization", "admin")
+		}
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		if recorder.Code != e.status || recorder.Body.String() != e.body {
+			t.Errorf("%s %s: expected %d '%s', got %d '%s'", e.method, e.path, e.status, e.body, recorder.Code, recorder.Body.String())
+		}
+		if strings.Join(calls, " ") != e.calls {
+			t.Errorf("%s %s: expected middleware '%s', got '%s'", e.method, e.path, e.calls, strings.Join(calls, " "))
+		}
+	}
+}
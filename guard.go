@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"maps"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 	"strconv"
@@ -67,6 +68,7 @@ type Viewer interface {
 	ViewSelect(context.Context) *View
 }
 
+// Deprecated: AllJson routes by ".json" suffixes, such as "/create.json", with GET and POST only. Use Resource.
 func AllJson[C any, T Viewer, FormCreate any, FormDelete any, FormEdit any]() func(*Strand) error {
 	handleCreate := CreateJson[T, FormCreate]()
 	handleDelete := DeleteJson[T, FormDelete]()
@@ -103,7 +105,11 @@ func Create[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 	if Database() == nil {
 		panic("trance: Database not registered. Please call 'trance.UseDatabase(*sql.DB)' before 'trance.Create[T Viewer, F any](FormRenderer[T])'")
 	}
+	return createHandler[T, F](renderer, false)
+}
 
+// createHandler inserts a record from a form. Resource routes respond with 201 Created and a Location header.
+func createHandler[T Viewer, F any](renderer FormRenderer[T], resource bool) func(*Strand) error {
 	form := Use[F]()
 	weave := Use[T]()
 
@@ -160,17 +166,21 @@ func Create[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 			}
 		}
 
-		results, _, err := Query[T]().Insert(record).Collect()
-		if err != nil {
+		if _, _, err := Query[T]().Insert(record).Collect(); err != nil {
 			renderer.RenderError(s.Response, s.Request(), err)
 			return nil
 		}
-		id, _ := results.LastInsertId()
+		// Insert sets zero integer primary keys, so the record holds its primary key either way.
+		id := weave.primaryValue(reflect.ValueOf(record).Elem(), false).Interface()
 		record, err = Query[T]().Filter(weave.PrimaryColumn, "=", id).First().Collect()
 		if err != nil {
 			renderer.RenderError(s.Response, s.Request(), err)
 			return nil
 		}
+		if resource {
+			s.Response.Header().Set("Location", strings.TrimSuffix(requestPath(s.Request()), "/")+"/"+url.PathEscape(fmt.Sprint(id)))
+			s.Response = &defaultStatusWriter{ResponseWriter: s.Response, status: http.StatusCreated}
+		}
 
 		// After.
 		if mfv, ok := any(modelForm).(FormWithAfter[T]); ok {
@@ -187,6 +197,15 @@ func Create[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 	}
 }
 
+// requestPath returns the escaped path the client requested, including prefixes removed by http.StripPrefix, such as
+// under App.Mount.
+func requestPath(r *http.Request) string {
+	if requestUri, err := url.ParseRequestURI(r.RequestURI); err == nil && requestUri.Path != "" {
+		return requestUri.EscapedPath()
+	}
+	return r.URL.EscapedPath()
+}
+
 func Database() *sql.DB {
 	return db_
 }
@@ -195,7 +214,12 @@ func Delete[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 	if Database() == nil {
 		panic("trance: Database not registered. Please call 'trance.UseDatabase(*sql.DB)' before 'trance.Delete[T Viewer, F any](ResponseRenderer[T])'")
 	}
+	return deleteHandler[T, F](renderer, false)
+}
 
+// deleteHandler deletes a record. Resource routes skip form validation, as DELETE requests have no body, and respond
+// with 204 No Content.
+func deleteHandler[T Viewer, F any](renderer FormRenderer[T], resource bool) func(*Strand) error {
 	form := Use[F]()
 	weave := Use[T]()
 
@@ -206,8 +230,8 @@ func Delete[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 	}
 
 	return func(s *Strand) error {
-		if s.Request().Method != "POST" {
-			return ErrorMethodNotAllowed{AllowedMethod: "POST"}
+		if s.Request().Method != "POST" && s.Request().Method != "DELETE" {
+			return ErrorMethodNotAllowed{AllowedMethod: "DELETE, POST"}
 		}
 		var record *T
 		var temp T
@@ -221,10 +245,13 @@ func Delete[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 		}
 
 		// Validate Form.
-		modelForm, err := form.Validate(s.Request())
-		if err != nil {
-			renderer.RenderFormErrors(s.Response, s.Request(), err)
-			return nil
+		modelForm := new(F)
+		if !resource {
+			modelForm, err = form.Validate(s.Request())
+			if err != nil {
+				renderer.RenderFormErrors(s.Response, s.Request(), err)
+				return nil
+			}
 		}
 
 		// Guard Form.
@@ -249,7 +276,7 @@ func Delete[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 
 		// Delete.
 		value := reflect.ValueOf(record).Elem()
-		id := value.FieldByName(weave.PrimaryField).Interface()
+		id := weave.primaryValue(value, false).Interface()
 		err = Query[T]().Filter(weave.PrimaryColumn, "=", id).Delete().Error
 		if err != nil {
 			renderer.RenderError(s.Response, s.Request(), err)
@@ -266,6 +293,10 @@ func Delete[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 		}
 
 		// Render.
+		if resource {
+			s.Response.WriteHeader(http.StatusNoContent)
+			return nil
+		}
 		renderer.RenderFind(s.Response, s.Request(), weave, view, record)
 		return nil
 	}
@@ -287,8 +318,8 @@ func Edit[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 
 	// Edit.
 	return func(s *Strand) error {
-		if s.Request().Method != "POST" {
-			return ErrorMethodNotAllowed{AllowedMethod: "POST"}
+		if method := s.Request().Method; method != "PATCH" && method != "POST" && method != "PUT" {
+			return ErrorMethodNotAllowed{AllowedMethod: "PATCH, POST, PUT"}
 		}
 		var temp T
 		view := temp.ViewSelect(s.Context)
@@ -300,8 +331,21 @@ func Edit[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 			return nil
 		}
 
-		// Validate Form.
-		modelForm, err := form.Validate(s.Request())
+		// Validate Form. PATCH requests only update the fields present in the body, so the form has zero values for
+		// absent fields.
+		var dataForm map[string]any
+		var modelForm *F
+		if s.Request().Method == "PATCH" {
+			dataForm, err = form.ValidatePartial(s.Request())
+			if err == nil {
+				modelForm, err = form.ScanMap(dataForm)
+			}
+		} else {
+			modelForm, err = form.Validate(s.Request())
+			if err == nil {
+				dataForm, err = form.ToMap(modelForm)
+			}
+		}
 		if err != nil {
 			renderer.RenderFormErrors(s.Response, s.Request(), err)
 			return nil
@@ -318,16 +362,12 @@ func Edit[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 		}
 
 		// Merge form and record.
-		data, err := weave.ToMap(record)
-		if err != nil {
-			renderer.RenderError(s.Response, s.Request(), err)
-			return nil
-		}
-		dataForm, err := form.ToMap(modelForm)
+		original, err := weave.ToMap(record)
 		if err != nil {
 			renderer.RenderError(s.Response, s.Request(), err)
 			return nil
 		}
+		data := maps.Clone(original)
 		maps.Copy(data, dataForm)
 		record, err = weave.ScanMap(data)
 		if err != nil {
@@ -345,21 +385,28 @@ func Edit[T Viewer, F any](renderer FormRenderer[T]) func(*Strand) error {
 			}
 		}
 
-		// Convert to map.
-		// TODO: Diff.
+		// Diff.
 		data, err = weave.ToMap(record)
 		if err != nil {
 			renderer.RenderError(s.Response, s.Request(), err)
 			return nil
 		}
+		changed := make(map[string]any, 0)
+		for column, value := range data {
+			if previous, ok := original[column]; !ok || !reflect.DeepEqual(previous, value) {
+				changed[column] = value
+			}
+		}
 
 		// Edit.
 		value := reflect.ValueOf(record).Elem()
-		id := value.FieldByName(weave.PrimaryField).Interface()
-		err = Query[T]().Filter(weave.PrimaryColumn, "=", id).UpdateMap(data).Error
-		if err != nil {
-			renderer.RenderError(s.Response, s.Request(), err)
-			return nil
+		id := weave.primaryValue(value, false).Interface()
+		if len(changed) > 0 {
+			err = Query[T]().Filter(weave.PrimaryColumn, "=", id).UpdateMap(changed).Error
+			if err != nil {
+				renderer.RenderError(s.Response, s.Request(), err)
+				return nil
+			}
 		}
 		record, err = Query[T]().Filter(weave.PrimaryColumn, "=", id).First().Collect()
 		if err != nil {
@@ -388,7 +435,7 @@ func fieldViewers[T any](weave *Weave[T]) map[string]Viewer {
 	guards := make(map[string]Viewer, 0)
 	for _, field := range weave.Fields {
 		if strings.HasPrefix(field.Type.String(), "trance.ForeignKey[") || strings.HasPrefix(field.Type.String(), "trance.NullForeignKey[") || strings.HasPrefix(field.Type.String(), "trance.OneToMany[") {
-			valueField := reflect.Indirect(value.FieldByName(field.Name))
+			valueField := fieldByIndex(value, field.Index, true)
 			qWeave := valueField.Addr().MethodByName("Weave").Call(nil)
 			qZero := reflect.Indirect(qWeave[0]).Addr().MethodByName("Zero").Call(nil)
 			if rowViewType, ok := reflect.Indirect(qZero[0]).Interface().(Viewer); ok {
@@ -437,6 +484,10 @@ func findWithView[T Viewer](r *http.Request, weave *Weave[T], view *View) (*T, e
 	query = querySorter(r, query, view)
 	query = queryLimiter(r, query, view)
 	query = queryOffsetter(r, query, view)
+	if id := r.PathValue("id"); id != "" && weave.PrimaryColumn != "" && !hasPrimaryKeyFilter(r, weave) {
+		// Routes such as "GET /posts/{id}" identify the record by primary key unless a primary key filter was given.
+		query = query.Filter(weave.PrimaryColumn, "=", id)
+	}
 
 	if query.Error != nil {
 		return nil, query.Error
@@ -583,6 +634,18 @@ func queryPrefetcher[T Viewer](_ *http.Request, query *QueryStream[T], view *Vie
 	return query
 }
 
+// hasPrimaryKeyFilter reports whether the request has a "filter." query parameter on the primary key column.
+func hasPrimaryKeyFilter[T any](r *http.Request, weave *Weave[T]) bool {
+	for key := range r.URL.Query() {
+		if keyCleaned, filtering := strings.CutPrefix(key, "filter."); filtering {
+			if column, _, _ := strings.Cut(keyCleaned, "__"); column == weave.PrimaryColumn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func queryFilter[T Viewer](r *http.Request, query *QueryStream[T], view *View) *QueryStream[T] {
 	if len(view.Config.Query.Filters) > 0 {
 		query.Config.Filters = view.Config.Query.Filters
@@ -0,0 +1,98 @@
+package trance
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Index describes a table index. Single column indexes can be declared with the `@index:"true"` field tag. Fields
+// sharing an `@index:"name"` tag form a composite index, in field order. Models declare other indexes by implementing
+// Indexer.
+type Index struct {
+	Columns []string
+	// Name defaults to the table and columns, such as "accounts_group_id_idx".
+	Name   string
+	Unique bool
+	// Where makes a partial index, such as "deleted_at IS NULL". It is not escaped.
+	Where string
+}
+
+// Constraint describes a table constraint. Check constraints set Check to a SQL expression, which is not escaped.
+// Unique constraints set Columns.
+type Constraint struct {
+	Check   string
+	Columns []string
+	Name    string
+}
+
+type Indexer interface {
+	Indexes() []Index
+}
+
+type Constrainer interface {
+	Constraints() []Constraint
+}
+
+// IndexName returns the default name for an index on table.
+func IndexName(table string, index Index) string {
+	suffix := "_idx"
+	if index.Unique {
+		suffix = "_key"
+	}
+	return table + "_" + strings.Join(index.Columns, "_") + suffix
+}
+
+// modelConstraints collects constraints from the Constrainer interface. Unnamed check constraints are numbered.
+func modelConstraints[T any](table string) []Constraint {
+	var model T
+	constrainer, ok := any(&model).(Constrainer)
+	if !ok {
+		return nil
+	}
+	constraints := slices.Clone(constrainer.Constraints())
+	checks := 0
+	for i, constraint := range constraints {
+		if constraint.Check != "" {
+			checks++
+			if constraint.Name == "" {
+				constraints[i].Name = fmt.Sprintf("%s_check%d", table, checks)
+			}
+		} else if constraint.Name == "" {
+			constraints[i].Name = IndexName(table, Index{Columns: constraint.Columns, Unique: true})
+		}
+	}
+	return constraints
+}
+
+// modelIndexes collects indexes from `@index` field tags and the Indexer interface.
+func modelIndexes[T any](table string, fields []reflect.StructField, columns []string) []Index {
+	indexes := make([]Index, 0)
+	named := make(map[string]int)
+	for i, field := range fields {
+		tagIndex := field.Tag.Get("@index")
+		if tagIndex == "" {
+			continue
+		}
+		if tagIndex == "true" {
+			indexes = append(indexes, Index{Columns: []string{columns[i]}})
+		} else if existing, ok := named[tagIndex]; ok {
+			indexes[existing].Columns = append(indexes[existing].Columns, columns[i])
+		} else {
+			named[tagIndex] = len(indexes)
+			indexes = append(indexes, Index{Columns: []string{columns[i]}, Name: tagIndex})
+		}
+	}
+
+	var model T
+	if indexer, ok := any(&model).(Indexer); ok {
+		indexes = append(indexes, indexer.Indexes()...)
+	}
+	for i, index := range indexes {
+		if index.Name == "" {
+			indexes[i].Name = IndexName(table, index)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,56 @@
+package trance
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testIndexModel struct {
+	Id        int64  `@:"id" @primary:"true"`
+	AccountId int64  `@:"account_id" @index:"true"`
+	Email     string `@:"email"`
+	GroupId   int64  `@:"group_id" @index:"group_slug_idx"`
+	Slug      string `@:"slug" @index:"group_slug_idx"`
+	Total     int64  `@:"total"`
+}
+
+func (*testIndexModel) Constraints() []Constraint {
+	return []Constraint{
+		{Check: "total >= 0"},
+		{Columns: []string{"email"}},
+	}
+}
+
+func (*testIndexModel) Indexes() []Index {
+	return []Index{
+		{Columns: []string{"email"}, Unique: true, Where: "total > 0"},
+	}
+}
+
+func TestModelIndexes(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testIndexModel](WeaveConfig{NoCache: true})
+
+	expectedIndexes := []Index{
+		{Columns: []string{"account_id"}, Name: "testindexmodel_account_id_idx"},
+		{Columns: []string{"group_id", "slug"}, Name: "group_slug_idx"},
+		{Columns: []string{"email"}, Name: "testindexmodel_email_key", Unique: true, Where: "total > 0"},
+	}
+	if !reflect.DeepEqual(weave.Indexes, expectedIndexes) {
+		t.Errorf("Expected '%#v', got '%#v'", expectedIndexes, weave.Indexes)
+	}
+
+	expectedConstraints := []Constraint{
+		{Check: "total >= 0", Name: "testindexmodel_check1"},
+		{Columns: []string{"email"}, Name: "testindexmodel_email_key"},
+	}
+	if !reflect.DeepEqual(weave.Constraints, expectedConstraints) {
+		t.Errorf("Expected '%#v', got '%#v'", expectedConstraints, weave.Constraints)
+	}
+
+	query := Query[testIndexModel]()
+	query.configure()
+	if !reflect.DeepEqual(query.Config.Indexes, expectedIndexes) {
+		t.Errorf("Expected query config indexes '%#v', got '%#v'", expectedIndexes, query.Config.Indexes)
+	}
+}
@@ -0,0 +1,219 @@
+package trance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type InspectModelsConfig struct {
+	Context context.Context
+	// Package defaults to "models".
+	Package string
+	// Tables limits the generated models. All tables are generated when empty.
+	Tables []string
+}
+
+type inspectCandidate struct {
+	expr   string
+	goType reflect.Type
+	tags   []string
+}
+
+// inspectCandidates are tried in order until the dialect maps one to the inspected column type.
+var inspectCandidates = []inspectCandidate{
+	{expr: "int64", goType: reflect.TypeFor[int64]()},
+	{expr: "int32", goType: reflect.TypeFor[int32]()},
+	{expr: "int16", goType: reflect.TypeFor[int16]()},
+	{expr: "int8", goType: reflect.TypeFor[int8]()},
+	{expr: "bool", goType: reflect.TypeFor[bool]()},
+	{expr: "float64", goType: reflect.TypeFor[float64]()},
+	{expr: "string", goType: reflect.TypeFor[string]()},
+	{expr: "trance.Decimal", goType: reflect.TypeFor[Decimal]()},
+	{expr: "time.Time", goType: reflect.TypeFor[time.Time]()},
+	{expr: "time.Time", goType: reflect.TypeFor[time.Time](), tags: []string{`@time_zone:"true"`}},
+	{expr: "[]int64", goType: reflect.TypeFor[[]int64]()},
+	{expr: "[]string", goType: reflect.TypeFor[[]string]()},
+}
+
+var inspectTypeArgs = regexp.MustCompile(`^[A-Z ]+\((\d+)(?:,(\d+))?\)`)
+
+// InspectModels reads the schema of the database and returns the source of a Go file declaring a model for each table.
+// Fields get `@`, `@primary`, `@length`, `@precision`, `@scale`, `@unique`, and `@default` tags, and single column
+// foreign keys become ForeignKey and NullForeignKey fields. Columns of types without a Go equivalent are string fields
+// with a `@type` tag.
+func InspectModels(config InspectModelsConfig) (string, error) {
+	db, inspector, err := useSchemaInspector()
+	if err != nil {
+		return "", err
+	}
+	if config.Package == "" {
+		config.Package = "models"
+	}
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tables, err := inspector.InspectSchema(ctx, db)
+	if err != nil {
+		return "", errors.Join(errors.New("trance: failed to inspect schema"), err)
+	}
+	ignoredTable := Use[MigrationLogs]().Table
+	tables = slices.DeleteFunc(tables, func(table SchemaTable) bool {
+		return table.Name == ignoredTable || (len(config.Tables) > 0 && !slices.Contains(config.Tables, table.Name))
+	})
+	for _, table := range config.Tables {
+		if !slices.ContainsFunc(tables, func(schemaTable SchemaTable) bool { return schemaTable.Name == table }) {
+			return "", fmt.Errorf("trance: table '%s' not found", table)
+		}
+	}
+
+	models := make(map[string]string, len(tables))
+	for _, table := range tables {
+		models[table.Name] = goFieldName(table.Name)
+	}
+
+	var body strings.Builder
+	for _, table := range tables {
+		model := models[table.Name]
+		fmt.Fprintf(&body, "type %s struct {\n", model)
+		fieldNames := make(map[string]bool, len(table.Columns))
+		for _, column := range table.Columns {
+			fieldName, fieldExpr, tags, err := inspectField(inspector, column, models)
+			if err != nil {
+				return "", errors.Join(fmt.Errorf("trance: failed to inspect column '%s' on table '%s'", column.Name, table.Name), err)
+			}
+			if fieldNames[fieldName] {
+				fieldName = goFieldName(column.Name)
+			}
+			fieldNames[fieldName] = true
+			tag := strings.Join(tags, " ")
+			if strings.Contains(tag, "`") {
+				tag = strconv.Quote(tag)
+			} else {
+				tag = "`" + tag + "`"
+			}
+			fmt.Fprintf(&body, "\t%s %s %s\n", fieldName, fieldExpr, tag)
+		}
+		body.WriteString("}\n\n")
+
+		if strings.ToLower(model) != table.Name {
+			fmt.Fprintf(&body, "func (%s) WeaveConfig() trance.WeaveConfig {\n\treturn trance.WeaveConfig{Table: %s}\n}\n\n", model, strconv.Quote(table.Name))
+		}
+	}
+
+	var source strings.Builder
+	fmt.Fprintf(&source, "package %s\n\n", config.Package)
+	imports := make([]string, 0)
+	if strings.Contains(body.String(), "time.Time") {
+		imports = append(imports, `"time"`)
+	}
+	if strings.Contains(body.String(), "trance.") {
+		imports = append(imports, `"github.com/evantbyrne/trance"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&source, "import (\n\t%s\n)\n\n", strings.Join(imports, "\n\n\t"))
+	}
+	source.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return "", errors.Join(errors.New("trance: failed to format models"), err)
+	}
+	return string(formatted), nil
+}
+
+// inspectField returns the field name, Go type, and tags for an inspected column.
+func inspectField(inspector SchemaInspector, column SchemaColumn, models map[string]string) (string, string, []string, error) {
+	fieldName := goFieldName(column.Name)
+	tags := []string{fmt.Sprintf("@:%s", strconv.Quote(column.Name))}
+	if column.Primary {
+		tags = append(tags, `@primary:"true"`)
+	}
+
+	var fieldExpr string
+	if model, ok := models[column.ForeignTable]; ok && !column.Primary {
+		if name, ok := strings.CutSuffix(column.Name, "_id"); ok && name != "" {
+			fieldName = goFieldName(name)
+		}
+		if column.Nullable {
+			fieldExpr = "trance.NullForeignKey[" + model + "]"
+		} else {
+			fieldExpr = "trance.ForeignKey[" + model + "]"
+		}
+	} else {
+		typeTags := make([]string, 0)
+		if match := inspectTypeArgs.FindStringSubmatch(column.Type); match != nil {
+			if match[2] != "" {
+				typeTags = append(typeTags, fmt.Sprintf(`@precision:"%s"`, match[1]), fmt.Sprintf(`@scale:"%s"`, match[2]))
+			} else {
+				typeTags = append(typeTags, fmt.Sprintf(`@length:"%s"`, match[1]), fmt.Sprintf(`@precision:"%s"`, match[1]))
+			}
+		}
+
+		for _, candidate := range inspectCandidates {
+			goType := candidate.goType
+			if column.Nullable && !column.Primary {
+				goType = reflect.PointerTo(goType)
+			}
+			candidateTags := slices.Concat(tags, typeTags, candidate.tags)
+			field := reflect.StructField{Name: fieldName, Tag: reflect.StructTag(strings.Join(candidateTags, " ")), Type: goType}
+			schemaColumn, err := inspector.SchemaColumn(column.Name, field)
+			if err != nil || !strings.EqualFold(schemaColumn.Type, column.Type) || schemaColumn.Nullable != column.Nullable {
+				continue
+			}
+			fieldExpr = candidate.expr
+			if goType.Kind() == reflect.Pointer {
+				fieldExpr = "*" + fieldExpr
+			}
+			for _, tag := range slices.Concat(typeTags, candidate.tags) {
+				// Keep tags that change the column type.
+				withoutTag := slices.DeleteFunc(slices.Clone(candidateTags), func(candidateTag string) bool { return candidateTag == tag })
+				field.Tag = reflect.StructTag(strings.Join(withoutTag, " "))
+				if without, err := inspector.SchemaColumn(column.Name, field); err != nil || without.Type != schemaColumn.Type {
+					tags = append(tags, tag)
+				}
+			}
+			break
+		}
+
+		if fieldExpr == "" {
+			columnType := column.Type
+			if column.Primary {
+				columnType += " PRIMARY KEY"
+			}
+			if column.Nullable {
+				fieldExpr = "*string"
+				columnType += " NULL"
+			} else {
+				columnType += " NOT NULL"
+			}
+			if column.Default != "" {
+				columnType += " DEFAULT " + column.Default
+			}
+			if column.Unique {
+				columnType += " UNIQUE"
+			}
+			if fieldExpr == "" {
+				fieldExpr = "string"
+			}
+			return fieldName, fieldExpr, append(tags, fmt.Sprintf("@type:%s", strconv.Quote(columnType))), nil
+		}
+	}
+
+	if column.Default != "" && !column.Primary {
+		tags = append(tags, fmt.Sprintf("@default:%s", strconv.Quote(column.Default)))
+	}
+	if column.Unique && !column.Primary {
+		tags = append(tags, `@unique:"true"`)
+	}
+	return fieldName, fieldExpr, tags, nil
+}
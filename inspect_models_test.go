@@ -0,0 +1,84 @@
+package trance
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestInspectModels(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	SetDialect(testInspectorDialect{tables: []SchemaTable{
+		{Name: "groups", Columns: []SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGINT"},
+			{Name: "name", Type: "TEXT", Unique: true},
+		}},
+		{Name: "migrationlogs", Columns: []SchemaColumn{{Name: "id", Primary: true, Type: "BIGINT"}}},
+		{Name: "user_accounts", Columns: []SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGINT"},
+			{ForeignColumn: "id", ForeignTable: "groups", Name: "group_id", Type: "BIGINT"},
+			{ForeignColumn: "id", ForeignTable: "user_accounts", Name: "parent_id", Nullable: true, Type: "BIGINT"},
+			{Default: "'none'", Name: "nickname", Nullable: true, Type: "TEXT"},
+			{Name: "location", Nullable: true, Type: "POINT"},
+		}},
+	}})
+
+	expected := "package models\n" +
+		"\n" +
+		"import (\n" +
+		"\t\"github.com/evantbyrne/trance\"\n" +
+		")\n" +
+		"\n" +
+		"type Groups struct {\n" +
+		"\tId   int64  `@:\"id\" @primary:\"true\"`\n" +
+		"\tName string `@:\"name\" @unique:\"true\"`\n" +
+		"}\n" +
+		"\n" +
+		"type UserAccounts struct {\n" +
+		"\tId       int64                               `@:\"id\" @primary:\"true\"`\n" +
+		"\tGroup    trance.ForeignKey[Groups]           `@:\"group_id\"`\n" +
+		"\tParent   trance.NullForeignKey[UserAccounts] `@:\"parent_id\"`\n" +
+		"\tNickname *string                             `@:\"nickname\" @default:\"'none'\"`\n" +
+		"\tLocation *string                             `@:\"location\" @type:\"POINT NULL\"`\n" +
+		"}\n" +
+		"\n" +
+		"func (UserAccounts) WeaveConfig() trance.WeaveConfig {\n" +
+		"\treturn trance.WeaveConfig{Table: \"user_accounts\"}\n" +
+		"}\n"
+	source, err := InspectModels(InspectModelsConfig{})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if source != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, source)
+	}
+
+	source, err = InspectModels(InspectModelsConfig{Package: "legacy", Tables: []string{"groups"}})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected = "package legacy\n" +
+		"\n" +
+		"type Groups struct {\n" +
+		"\tId   int64  `@:\"id\" @primary:\"true\"`\n" +
+		"\tName string `@:\"name\" @unique:\"true\"`\n" +
+		"}\n"
+	if source != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, source)
+	}
+
+	if _, err := InspectModels(InspectModelsConfig{Tables: []string{"missing"}}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
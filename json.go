@@ -0,0 +1,82 @@
+package trance
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSON stores a value as a JSON column. It maps to JSONB on Postgres, JSON on MySQL, and TEXT on SQLite.
+type JSON[T any] struct {
+	Data T
+}
+
+func (field JSON[T]) JsonValue() any {
+	return field.Data
+}
+
+func (field JSON[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(field.Data)
+}
+
+func (field *JSON[T]) Scan(src any) error {
+	var zero T
+	field.Data = zero
+	return unmarshalJsonColumn(src, &field.Data)
+}
+
+func (field *JSON[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &field.Data)
+}
+
+func (field JSON[T]) Value() (driver.Value, error) {
+	encoded, err := json.Marshal(field.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func (field JSON[T]) jsonColumn() {}
+
+type jsonColumner interface {
+	jsonColumn()
+}
+
+var jsonColumnerType = reflect.TypeOf((*jsonColumner)(nil)).Elem()
+
+// IsJsonField reports whether a field is stored as a JSON column, either by being a trance.JSON[T] or by having a
+// `@json:"true"` tag.
+func IsJsonField(field reflect.StructField) bool {
+	return field.Tag.Get("@json") == "true" || field.Type.Implements(jsonColumnerType)
+}
+
+func marshalJsonColumn(value reflect.Value) (any, error) {
+	if (value.Kind() == reflect.Pointer || value.Kind() == reflect.Map || value.Kind() == reflect.Slice) && value.IsNil() {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func unmarshalJsonColumn(src any, target any) error {
+	switch sv := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if len(sv) == 0 {
+			return nil
+		}
+		return json.Unmarshal(sv, target)
+	case string:
+		if sv == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(sv), target)
+	}
+	return fmt.Errorf("trance: unhandled type conversion in scan from '%T' to JSON", src)
+}
@@ -0,0 +1,158 @@
+package trance
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Handler handles a request. A wrapper, such as RecoverPanics, may also be applied to a single route's handler:
+//
+//	app.Get("/reports", TimeoutRequests(time.Minute)(reports))
+type Handler func(*Strand) error
+
+type requestIdKey struct{}
+
+// AssignRequestId assigns each request an ID, available from Strand.RequestId and echoed in the X-Request-Id response
+// header. An X-Request-Id request header of up to 128 characters is reused, so IDs can be traced across services.
+func AssignRequestId(next Handler) Handler {
+	return func(strand *Strand) error {
+		id := strand.Request().Header.Get("X-Request-Id")
+		if id == "" || len(id) > 128 {
+			random := make([]byte, 16)
+			if _, err := rand.Read(random); err != nil {
+				return err
+			}
+			id = hex.EncodeToString(random)
+		}
+		strand.Context = context.WithValue(strand.Context, requestIdKey{}, id)
+		strand.Response.Header().Set("X-Request-Id", id)
+		return next(strand)
+	}
+}
+
+// LogRequests logs the method, path, status and duration of each request, along with the request ID and any error.
+// Under App, the entry is logged once ErrorHandler has answered any error, so the status is the one it wrote. Otherwise,
+// when the handler returns an error before writing a response, the status is the one the default ErrorHandler writes.
+// Use it before RecoverPanics so that panics are logged.
+func LogRequests(logger *slog.Logger) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return func(strand *Strand) error {
+			start := time.Now()
+			response := &statusRecorder{ResponseWriter: strand.Response}
+			strand.Response = response
+			err := next(strand)
+			if strand.finally != nil {
+				// ErrorHandler writes through the recorder.
+				strand.finally = append(strand.finally, func() {
+					logRequest(logger, strand, start, response.status, err)
+				})
+				return err
+			}
+			strand.Response = response.ResponseWriter
+			logRequest(logger, strand, start, response.status, err)
+			return err
+		}
+	}
+}
+
+// logRequest logs a request for LogRequests. A status of 0 means no response was written.
+func logRequest(logger *slog.Logger, strand *Strand, start time.Time, status int, err error) {
+	if status == 0 {
+		status = http.StatusOK
+		if err != nil {
+			status = http.StatusInternalServerError
+			if errWithStatus, ok := err.(ErrorWithStatus); ok {
+				status = errWithStatus.Status()
+			}
+		}
+	}
+	attrs := []slog.Attr{
+		slog.String("method", strand.Request().Method),
+		slog.String("path", strand.Request().URL.Path),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if id := strand.RequestId(); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		var errPanic ErrorPanic
+		if errors.As(err, &errPanic) {
+			attrs = append(attrs, slog.Any("panic", errPanic.Value), slog.String("stack", string(errPanic.Stack)))
+		} else {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+	}
+	logger.LogAttrs(strand.Context, level, "request", attrs...)
+}
+
+// RecoverPanics returns ErrorPanic for a handler that panics, which ErrorHandler answers with a 500 response. A panic
+// with http.ErrAbortHandler is left to net/http.
+func RecoverPanics(next Handler) Handler {
+	return func(strand *Strand) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+				err = ErrorPanic{Stack: debug.Stack(), Value: value}
+			}
+		}()
+		return next(strand)
+	}
+}
+
+// TimeoutRequests cancels Strand.Context and the context of Strand.Request once a request has run for the duration.
+// Handlers should pass the context on, such as with QueryStream.Context. An error returned after the deadline becomes
+// ErrorServiceUnavailable.
+func TimeoutRequests(duration time.Duration) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return func(strand *Strand) error {
+			ctx, cancel := context.WithTimeout(strand.Context, duration)
+			defer cancel()
+			if request, ok := strand.Context.Value("Request").(*http.Request); ok {
+				ctx = context.WithValue(ctx, "Request", request.WithContext(ctx))
+			}
+			strand.Context = ctx
+			err := next(strand)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrorServiceUnavailable{Message: "Request timed out"}
+			}
+			return err
+		}
+	}
+}
+
+// statusRecorder records the status written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (recorder *statusRecorder) Write(body []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	return recorder.ResponseWriter.Write(body)
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,115 @@
+package trance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrappers(t *testing.T) {
+	var logs bytes.Buffer
+	var calls []string
+	app := &App{}
+	app.UseWrappers(AssignRequestId, LogRequests(slog.New(slog.NewTextHandler(&logs, nil))), RecoverPanics)
+	app.UseWrappers(func(next Handler) Handler {
+		return func(s *Strand) error {
+			calls = append(calls, "before")
+			err := next(s)
+			calls = append(calls, "after")
+			return err
+		}
+	})
+	app.UseMiddleware(func(s *Strand) error {
+		calls = append(calls, "middleware")
+		return nil
+	})
+	app.Get("/hello", func(s *Strand) error {
+		calls = append(calls, "handler")
+		return s.WriteHtml("Hello " + s.RequestId())
+	})
+	app.Get("/panic", func(s *Strand) error {
+		panic("boom")
+	})
+	app.Get("/slow", TimeoutRequests(time.Millisecond)(func(s *Strand) error {
+		<-s.Context.Done()
+		return s.Context.Err()
+	}))
+
+	request := httptest.NewRequest("GET", "/hello", nil)
+	request.Header.Set("X-Request-Id", "abc")
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	if recorder.Body.String() != "Hello abc" || recorder.Header().Get("X-Request-Id") != "abc" {
+		t.Errorf("Unexpected response '%s' with request ID '%s'", recorder.Body.String(), recorder.Header().Get("X-Request-Id"))
+	}
+	if strings.Join(calls, " ") != "before middleware handler after" {
+		t.Errorf("Unexpected order '%s'", strings.Join(calls, " "))
+	}
+	if !strings.Contains(logs.String(), "level=INFO msg=request method=GET path=/hello status=200 duration=") || !strings.Contains(logs.String(), "request_id=abc") {
+		t.Errorf("Unexpected log '%s'", logs.String())
+	}
+
+	logs.Reset()
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("GET", "/panic", nil))
+	if recorder.Code != http.StatusInternalServerError || recorder.Body.String() != "Internal server error\n" {
+		t.Errorf("Expected 500 for panic, got %d '%s'", recorder.Code, recorder.Body.String())
+	}
+	if id := recorder.Header().Get("X-Request-Id"); len(id) != 32 {
+		t.Errorf("Expected generated request ID, got '%s'", id)
+	}
+	if !strings.Contains(logs.String(), "level=ERROR msg=request method=GET path=/panic status=500") || !strings.Contains(logs.String(), "panic=boom") {
+		t.Errorf("Unexpected log '%s'", logs.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("GET", "/slow", nil))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Body.String() != "Request timed out\n" {
+		t.Errorf("Expected 503 for timeout, got %d '%s'", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestLogRequestsErrorHandler(t *testing.T) {
+	var logs bytes.Buffer
+	app := &App{
+		ErrorHandler: func(s *Strand) {
+			s.Response.WriteHeader(http.StatusTeapot)
+		},
+	}
+	app.UseWrappers(LogRequests(slog.New(slog.NewTextHandler(&logs, nil))))
+	app.Get("/fail", func(s *Strand) error {
+		return errors.New("failed")
+	})
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("GET", "/fail", nil))
+	if recorder.Code != http.StatusTeapot {
+		t.Errorf("Expected %d, got %d", http.StatusTeapot, recorder.Code)
+	}
+	if !strings.Contains(logs.String(), "level=INFO msg=request method=GET path=/fail status=418") || !strings.Contains(logs.String(), "error=failed") {
+		t.Errorf("Unexpected log '%s'", logs.String())
+	}
+}
+
+func TestTimeoutRequests(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	strand := &Strand{Context: context.WithValue(context.Background(), "Request", request)}
+	err := TimeoutRequests(time.Hour)(func(s *Strand) error {
+		if _, ok := s.Context.Deadline(); !ok {
+			t.Error("Expected context deadline")
+		}
+		if _, ok := s.Request().Context().Deadline(); !ok {
+			t.Error("Expected request context deadline")
+		}
+		return errors.New("failed")
+	})(strand)
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("Expected error before deadline to pass through, got %#v", err)
+	}
+}
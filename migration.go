@@ -1,9 +1,14 @@
 package trance
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,6 +17,21 @@ type Migration interface {
 	Up() error
 }
 
+// MigrationDialect is implemented by dialects that can run migrations safely while other processes migrate the same
+// database, such as replicas starting together during a deploy.
+type MigrationDialect interface {
+	// LockMigrations blocks until no other process holds the migration lock, and returns a function that releases it.
+	LockMigrations(context.Context, *sql.DB) (func() error, error)
+	// TransactionalDdl reports whether schema changes can be rolled back inside a transaction.
+	TransactionalDdl() bool
+}
+
+// MigrationIdentifier is implemented by migrations with an ID that is stable when their Go type is renamed or moved.
+type MigrationIdentifier interface {
+	MigrationId() string
+}
+
+// MigrationLogs records each applied and reverted migration. MigrationType holds the ID from MigrationId.
 type MigrationLogs struct {
 	CreatedAt     time.Time `@:"created_at"`
 	Direction     string    `@:"direction" @length:"10"`
@@ -19,86 +39,309 @@ type MigrationLogs struct {
 	MigrationType string    `@:"migration_type" @length:"255"`
 }
 
+type MigrationState string
+
+const (
+	MigrationApplied MigrationState = "applied"
+	MigrationPending MigrationState = "pending"
+	// MigrationUnknown is the state of applied migrations that are not in the list of migrations.
+	MigrationUnknown MigrationState = "unknown"
+)
+
+type MigrationStatusEntry struct {
+	// AppliedAt is zero for pending migrations.
+	AppliedAt time.Time
+	Id        string
+	State     MigrationState
+}
+
+// MigrationUnknownError is returned when migrations that are not in the list of migrations have been applied.
+type MigrationUnknownError struct {
+	Ids []string
+}
+
+func (err MigrationUnknownError) Error() string {
+	return "trance: applied migrations not found: " + strings.Join(err.Ids, ", ")
+}
+
+type migrationRun struct {
+	direction string
+	index     int
+}
+
+// migrate returns the Up or Down method of the migration to run.
+func (run migrationRun) migrate(migrations []Migration) func() error {
+	if run.direction == "down" {
+		return migrations[run.index].Down
+	}
+	return migrations[run.index].Up
+}
+
+var migrationTransaction_ atomic.Pointer[sql.Tx]
+
+// MigrationTransaction returns the transaction of the running migration, or nil when migrations are not running inside
+// a transaction. Only MigrationQuery, MigrationQueryWith, and MigrationExec join it, so queries made elsewhere while
+// migrations run, such as by background workers, are unaffected.
+func MigrationTransaction() *sql.Tx {
+	return migrationTransaction_.Load()
+}
+
+// MigrateDown reverts every applied migration, latest first. See MigrateDownSteps to revert only the latest, and
+// MigrateUp for locking and transactions.
 func MigrateDown(migrations []Migration) ([]string, error) {
-	defer PurgeWeaves()
-	logs, latestIndex, err := migrateSetup(migrations)
+	return MigrateDownSteps(migrations, len(migrations))
+}
+
+// MigrateDownSteps reverts up to steps applied migrations, latest first. See MigrateUp for locking and transactions.
+func MigrateDownSteps(migrations []Migration, steps int) ([]string, error) {
+	return migrateWith(migrations, planMigrateDown(migrations, steps))
+}
+
+// migrateLocked runs migrate while holding the migration lock of the dialect. The lock is released even if migrate
+// panics.
+func migrateLocked(migrate func() ([]string, error)) (logs []string, err error) {
+	db := Database()
+	if db == nil {
+		return nil, UseDatabaseError{}
+	}
+	locker, ok := defaultDialect.(MigrationDialect)
+	if !ok {
+		return migrate()
+	}
+
+	unlock, err := locker.LockMigrations(context.Background(), db)
 	if err != nil {
-		return logs, err
+		return nil, errors.Join(errors.New("trance: migrations setup: failed to acquire migration lock"), err)
 	}
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			err = errors.Join(err, errors.New("trance: failed to release migration lock"), unlockErr)
+		}
+	}()
+	return migrate()
+}
 
-	for i := latestIndex; i > -1; i-- {
-		PurgeWeaves()
-		migrationType := reflect.TypeOf(migrations[i]).String()
-		logs = append(logs, "Migrating down to "+migrationType+"...")
-		if err := migrations[i].Down(); err != nil {
-			return logs, errors.Join(fmt.Errorf("migration %s: failed", migrationType), err)
+// migrateRun runs a migration and logs it. Both happen inside one transaction when the dialect supports transactional
+// DDL, so a failed migration is neither partially applied nor logged. The transaction is rolled back if run panics.
+func migrateRun(id string, direction string, run func() error) error {
+	var tx *sql.Tx
+	if dialect, ok := defaultDialect.(MigrationDialect); ok && dialect.TransactionalDdl() {
+		var err error
+		if tx, err = Database().Begin(); err != nil {
+			return errors.Join(fmt.Errorf("trance: migration %s: failed to begin transaction", id), err)
 		}
-		err := Query[MigrationLogs]().Insert(&MigrationLogs{
-			CreatedAt:     time.Now(),
-			Direction:     "down",
-			MigrationType: migrationType,
-		}).Error
-		if err != nil {
-			return logs, errors.Join(fmt.Errorf("migration %s: failed to insert migration logs", migrationType), err)
+		migrationTransaction_.Store(tx)
+		defer migrationTransaction_.Store(nil)
+		// Rollback is a no-op once the transaction has been committed or rolled back.
+		defer tx.Rollback()
+	}
+	rollback := func() error {
+		if tx != nil {
+			return tx.Rollback()
 		}
+		return nil
 	}
 
-	return logs, nil
+	if err := run(); err != nil {
+		return errors.Join(fmt.Errorf("trance: migration %s: failed", id), err, rollback())
+	}
+	err := Query[MigrationLogs]().Transaction(tx).Insert(&MigrationLogs{
+		CreatedAt:     time.Now(),
+		Direction:     direction,
+		MigrationType: id,
+	}).Error
+	if err != nil {
+		return errors.Join(fmt.Errorf("trance: migration %s: failed to insert migration logs", id), err, rollback())
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return errors.Join(fmt.Errorf("trance: migration %s: failed to commit", id), err)
+		}
+	}
+	return nil
 }
 
-func migrateSetup(migrations []Migration) ([]string, int, error) {
-	logs := make([]string, 0)
+// MigrateTo applies or reverts migrations until the migration with an ID is the latest applied. Applied migrations
+// after it are reverted, latest first, and then pending migrations up to it are applied in order. See MigrateUp for
+// locking and transactions.
+func MigrateTo(migrations []Migration, id string) ([]string, error) {
+	return migrateWith(migrations, planMigrateTo(migrations, id))
+}
 
-	err := Query[MigrationLogs]().TableCreate(TableCreateConfig{IfNotExists: true}).Error
+// MigrateUp applies pending migrations in order. When the dialect implements MigrationDialect, a migration lock is held
+// for the duration, and each migration runs inside a transaction with its migration log if the dialect supports
+// transactional DDL. Migrations should use MigrationQuery, MigrationQueryWith, or MigrationExec to join it. Nothing
+// runs while migrations that are not in the list have been applied.
+func MigrateUp(migrations []Migration) ([]string, error) {
+	return migrateWith(migrations, planMigrateUp(migrations))
+}
+
+// migratePlan returns the status of migrations and the migrations to run.
+func migratePlan(migrations []Migration, plan func([]MigrationStatusEntry) ([]migrationRun, error)) ([]MigrationStatusEntry, []migrationRun, error) {
+	statuses, err := MigrationStatus(migrations)
 	if err != nil {
-		return nil, -1, errors.Join(errors.New("trance: migrations setup: failed to create table for migration logs"), err)
+		return nil, nil, err
+	}
+	unknown := make([]string, 0)
+	for _, status := range statuses[len(migrations):] {
+		unknown = append(unknown, status.Id)
 	}
+	if len(unknown) > 0 {
+		return nil, nil, MigrationUnknownError{Ids: unknown}
+	}
+	runs, err := plan(statuses)
+	return statuses, runs, err
+}
 
-	latest, err := Query[MigrationLogs]().Sort("-id").CollectFirst()
-	latestIndex := -1
-	if err != nil {
-		if _, notFound := err.(ErrorNotFound); !notFound {
-			return nil, -1, errors.Join(errors.New("trance: migrations setup: failed to get migrations list:"), err)
+// migrateWith holds the migration lock while running the migrations planned from their status.
+func migrateWith(migrations []Migration, plan func([]MigrationStatusEntry) ([]migrationRun, error)) ([]string, error) {
+	defer PurgeWeaves()
+	return migrateLocked(func() ([]string, error) {
+		statuses, runs, err := migratePlan(migrations, plan)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		for i, migration := range migrations {
-			if latest.MigrationType == reflect.TypeOf(migration).String() {
-				if latest.Direction == "down" {
-					latestIndex = i - 1
-				} else {
-					latestIndex = i
-				}
-				break
+
+		logs := make([]string, 0, len(runs))
+		for _, run := range runs {
+			PurgeWeaves()
+			id := statuses[run.index].Id
+			logs = append(logs, "Migrating "+run.direction+" to "+id+"...")
+			if err := migrateRun(id, run.direction, run.migrate(migrations)); err != nil {
+				return logs, err
 			}
 		}
+		return logs, nil
+	})
+}
+
+// MigrationExec executes a statement inside the running migration, such as raw SQL that Query cannot build. It uses
+// the migration transaction when there is one, and only records the statement during dry runs.
+func MigrationExec(query string, args ...any) (sql.Result, error) {
+	if recorder := migrationRecorder_.Load(); recorder != nil {
+		return recorder.exec(query, args...)
+	}
+	if tx := MigrationTransaction(); tx != nil {
+		return tx.Exec(query, args...)
 	}
+	db := Database()
+	if db == nil {
+		return nil, UseDatabaseError{}
+	}
+	return db.Exec(query, args...)
+}
 
-	return logs, latestIndex, nil
+// MigrationQuery returns a query for use inside a migration. It joins the migration transaction when there is one, and
+// only records statements during dry runs. Queries created with Query always use the database directly.
+func MigrationQuery[T any]() *QueryStream[T] {
+	query := Query[T]().Transaction(MigrationTransaction())
+	query.recorder = migrationRecorder_.Load()
+	return query
 }
 
-func MigrateUp(migrations []Migration) ([]string, error) {
-	defer PurgeWeaves()
-	logs, latestIndex, err := migrateSetup(migrations)
+// MigrationQueryWith returns a query with a weave config for use inside a migration. See MigrationQuery.
+func MigrationQueryWith[T any](config WeaveConfig) *QueryStream[T] {
+	query := QueryWith[T](config).Transaction(MigrationTransaction())
+	query.recorder = migrationRecorder_.Load()
+	return query
+}
+
+// MigrationId returns the ID a migration is logged with. Migrations implementing MigrationIdentifier use their own ID,
+// and others use their Go type, such as "migrations.Migration0001", which changes if the type is renamed or moved.
+func MigrationId(migration Migration) string {
+	if identifier, ok := migration.(MigrationIdentifier); ok {
+		return identifier.MigrationId()
+	}
+	return reflect.TypeOf(migration).String()
+}
+
+// MigrationStatus returns an entry for each migration in order, followed by applied migrations that are not in the
+// list, in the order they were applied.
+func MigrationStatus(migrations []Migration) ([]MigrationStatusEntry, error) {
+	err := Query[MigrationLogs]().TableCreate(TableCreateConfig{IfNotExists: true}).Error
 	if err != nil {
-		return logs, err
+		return nil, errors.Join(errors.New("trance: migrations setup: failed to create table for migration logs"), err)
+	}
+	migrationLogs, err := Query[MigrationLogs]().Sort("id").Collect()
+	if err != nil {
+		return nil, errors.Join(errors.New("trance: migrations setup: failed to get migrations list"), err)
 	}
 
-	for i := latestIndex + 1; i < len(migrations); i++ {
-		PurgeWeaves()
-		migrationType := reflect.TypeOf(migrations[i]).String()
-		logs = append(logs, "Migrating up to "+migrationType+"...")
-		if err := migrations[i].Up(); err != nil {
-			return logs, errors.Join(fmt.Errorf("trance: migration %s: failed", migrationType), err)
+	applied := make(map[string]time.Time)
+	appliedOrder := make([]string, 0)
+	for _, migrationLog := range migrationLogs {
+		appliedOrder = slices.DeleteFunc(appliedOrder, func(id string) bool { return id == migrationLog.MigrationType })
+		if migrationLog.Direction == "up" {
+			applied[migrationLog.MigrationType] = migrationLog.CreatedAt
+			appliedOrder = append(appliedOrder, migrationLog.MigrationType)
+		} else {
+			delete(applied, migrationLog.MigrationType)
 		}
-		err := Query[MigrationLogs]().Insert(&MigrationLogs{
-			CreatedAt:     time.Now(),
-			Direction:     "up",
-			MigrationType: migrationType,
-		}).Error
-		if err != nil {
-			return logs, errors.Join(fmt.Errorf("trance: migration %s: failed to insert migration logs", migrationType), err)
+	}
+
+	statuses := make([]MigrationStatusEntry, 0, len(migrations))
+	ids := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		id := MigrationId(migration)
+		if ids[id] {
+			return nil, fmt.Errorf("trance: duplicate migration '%s'", id)
+		}
+		ids[id] = true
+		if appliedAt, ok := applied[id]; ok {
+			statuses = append(statuses, MigrationStatusEntry{AppliedAt: appliedAt, Id: id, State: MigrationApplied})
+		} else {
+			statuses = append(statuses, MigrationStatusEntry{Id: id, State: MigrationPending})
+		}
+	}
+	for _, id := range appliedOrder {
+		if !ids[id] {
+			statuses = append(statuses, MigrationStatusEntry{AppliedAt: applied[id], Id: id, State: MigrationUnknown})
+		}
+	}
+	return statuses, nil
+}
+
+func planMigrateDown(migrations []Migration, steps int) func([]MigrationStatusEntry) ([]migrationRun, error) {
+	return func(statuses []MigrationStatusEntry) ([]migrationRun, error) {
+		runs := make([]migrationRun, 0)
+		for i := len(migrations) - 1; i > -1 && len(runs) < steps; i-- {
+			if statuses[i].State == MigrationApplied {
+				runs = append(runs, migrationRun{direction: "down", index: i})
+			}
+		}
+		return runs, nil
+	}
+}
+
+func planMigrateTo(migrations []Migration, id string) func([]MigrationStatusEntry) ([]migrationRun, error) {
+	return func(statuses []MigrationStatusEntry) ([]migrationRun, error) {
+		target := slices.IndexFunc(statuses[:len(migrations)], func(status MigrationStatusEntry) bool { return status.Id == id })
+		if target == -1 {
+			return nil, fmt.Errorf("trance: migration '%s' not found", id)
 		}
+		runs := make([]migrationRun, 0)
+		for i := len(migrations) - 1; i > target; i-- {
+			if statuses[i].State == MigrationApplied {
+				runs = append(runs, migrationRun{direction: "down", index: i})
+			}
+		}
+		for i := 0; i <= target; i++ {
+			if statuses[i].State == MigrationPending {
+				runs = append(runs, migrationRun{direction: "up", index: i})
+			}
+		}
+		return runs, nil
 	}
+}
 
-	return logs, nil
+func planMigrateUp(migrations []Migration) func([]MigrationStatusEntry) ([]migrationRun, error) {
+	return func(statuses []MigrationStatusEntry) ([]migrationRun, error) {
+		runs := make([]migrationRun, 0)
+		for i := range migrations {
+			if statuses[i].State == MigrationPending {
+				runs = append(runs, migrationRun{direction: "up", index: i})
+			}
+		}
+		return runs, nil
+	}
 }
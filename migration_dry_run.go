@@ -0,0 +1,120 @@
+package trance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MigrationScript holds the statements a migration would run in one direction.
+type MigrationScript struct {
+	Direction  string
+	Id         string
+	Statements []MigrationStatement
+}
+
+// String renders the script as SQL, with arguments of parameterized statements in comments.
+func (script MigrationScript) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "-- Migrate %s to %s\n", script.Direction, script.Id)
+	for _, statement := range script.Statements {
+		s.WriteString(statement.Sql)
+		s.WriteString(";")
+		if len(statement.Args) > 0 {
+			fmt.Fprintf(&s, " -- %v", statement.Args)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// MigrationDryRunError is returned by statements that would execute against the database during a dry run instead of
+// being recorded, such as those of queries created with Query rather than MigrationQuery.
+type MigrationDryRunError struct{}
+
+func (err MigrationDryRunError) Error() string {
+	return "trance: statement would execute during a migration dry run"
+}
+
+type MigrationStatement struct {
+	Args []any
+	Sql  string
+}
+
+type migrationRecorder struct {
+	mutex      sync.Mutex
+	statements []MigrationStatement
+}
+
+func (recorder *migrationRecorder) exec(query string, args ...any) (recordedResult, error) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.statements = append(recorder.statements, MigrationStatement{Args: args, Sql: query})
+	return recordedResult{}, nil
+}
+
+// recordedResult is the result of statements recorded during dry runs.
+type recordedResult struct{}
+
+func (result recordedResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (result recordedResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+var migrationRecorder_ atomic.Pointer[migrationRecorder]
+
+// MigrateDownDryRun returns the scripts MigrateDown would run, without running them.
+func MigrateDownDryRun(migrations []Migration) ([]MigrationScript, error) {
+	return MigrateDownStepsDryRun(migrations, len(migrations))
+}
+
+// MigrateDownStepsDryRun returns the scripts MigrateDownSteps would run, without running them.
+func MigrateDownStepsDryRun(migrations []Migration, steps int) ([]MigrationScript, error) {
+	return migrateDryRun(migrations, planMigrateDown(migrations, steps))
+}
+
+// MigrateToDryRun returns the scripts MigrateTo would run, without running them.
+func MigrateToDryRun(migrations []Migration, id string) ([]MigrationScript, error) {
+	return migrateDryRun(migrations, planMigrateTo(migrations, id))
+}
+
+// MigrateUpDryRun returns the scripts MigrateUp would run, without running them. Statements executed with queries
+// created during the migration and with MigrationExec are recorded instead of executed, while reads still query the
+// database. Other statements fail with MigrationDryRunError. The table for migration logs is created if it does not
+// exist, so the status of migrations can be read.
+func MigrateUpDryRun(migrations []Migration) ([]MigrationScript, error) {
+	return migrateDryRun(migrations, planMigrateUp(migrations))
+}
+
+// migrateRecorded runs a migration while its statements are recorded. Queries created at the same time elsewhere, such
+// as by HTTP handlers, are recorded too, so dry runs should not run alongside a serving application.
+func migrateRecorded(recorder *migrationRecorder, migrate func() error) error {
+	migrationRecorder_.Store(recorder)
+	defer migrationRecorder_.Store(nil)
+	return migrate()
+}
+
+func migrateDryRun(migrations []Migration, plan func([]MigrationStatusEntry) ([]migrationRun, error)) ([]MigrationScript, error) {
+	defer PurgeWeaves()
+	statuses, runs, err := migratePlan(migrations, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make([]MigrationScript, 0, len(runs))
+	for _, run := range runs {
+		PurgeWeaves()
+		id := statuses[run.index].Id
+		recorder := &migrationRecorder{statements: make([]MigrationStatement, 0)}
+		if err := migrateRecorded(recorder, run.migrate(migrations)); err != nil {
+			return scripts, errors.Join(fmt.Errorf("trance: migration %s: failed", id), err)
+		}
+		scripts = append(scripts, MigrationScript{Direction: run.direction, Id: id, Statements: recorder.statements})
+	}
+	return scripts, nil
+}
@@ -0,0 +1,122 @@
+package trance
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type testMigrationSeed struct{}
+
+func (m testMigrationSeed) Up() error {
+	if _, err := MigrationExec("UPDATE a SET b = $1", 2); err != nil {
+		return err
+	}
+	if MigrationQuery[testMigrationModel]().recorder == nil || Query[testMigrationModel]().recorder != nil {
+		return errors.New("expected only migration queries to be recorded")
+	}
+	return MigrationQuery[testMigrationModel]().Insert(&testMigrationModel{}).Error
+}
+
+func (m testMigrationSeed) Down() error {
+	return nil
+}
+
+func TestMigrateUpDryRun(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testMigrationDialect{transactionalDdl: true})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(sqlmock.NewRows([]string{"created_at", "direction", "id", "migration_type"}))
+
+	migrations := []Migration{
+		testMigrationCreate{},
+		SqlMigration{Id: "0002_sql", UpSql: "CREATE TABLE a (b INTEGER);\nCREATE INDEX a_b_idx ON a (b);"},
+		testMigrationSeed{},
+	}
+	scripts, err := MigrateUpDryRun(migrations)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []MigrationScript{
+		{Direction: "up", Id: "trance.testMigrationCreate", Statements: []MigrationStatement{{Sql: "CREATE|testmigrationmodel|"}}},
+		{Direction: "up", Id: "0002_sql", Statements: []MigrationStatement{{Sql: "CREATE TABLE a (b INTEGER)"}, {Sql: "CREATE INDEX a_b_idx ON a (b)"}}},
+		{Direction: "up", Id: "trance.testMigrationSeed", Statements: []MigrationStatement{{Args: []any{2}, Sql: "UPDATE a SET b = $1"}, {Sql: "INSERT|testmigrationmodel|"}}},
+	}
+	if !reflect.DeepEqual(expected, scripts) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, scripts)
+	}
+	if expected := "-- Migrate up to trance.testMigrationSeed\nUPDATE a SET b = $1; -- [2]\nINSERT|testmigrationmodel|;\n"; scripts[2].String() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, scripts[2].String())
+	}
+	if migrationRecorder_.Load() != nil {
+		t.Error("Expected recorder to be cleared")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type testMigrationStale struct {
+	query *QueryStream[testMigrationModel]
+}
+
+func (m testMigrationStale) Up() error {
+	return m.query.TableCreate().Error
+}
+
+func (m testMigrationStale) Down() error {
+	return nil
+}
+
+func TestMigrateUpDryRunStaleQuery(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testMigrationDialect{transactionalDdl: true})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(sqlmock.NewRows([]string{"created_at", "direction", "id", "migration_type"}))
+
+	_, err = MigrateUpDryRun([]Migration{testMigrationStale{query: Query[testMigrationModel]()}})
+	if !errors.As(err, &MigrationDryRunError{}) {
+		t.Errorf("Expected MigrationDryRunError, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrateRecordedPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic")
+		}
+		if migrationRecorder_.Load() != nil {
+			t.Error("Expected recorder to be cleared after a panic")
+		}
+	}()
+	migrateRecorded(&migrationRecorder{}, func() error {
+		panic("failed")
+	})
+}
@@ -0,0 +1,367 @@
+package trance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+type MakeMigrationConfig struct {
+	Context context.Context
+	// DropTables lists tables without a registered model that the migration drops. Other such tables are left alone,
+	// so tables of models that were not registered, or of other applications sharing the database, are never dropped.
+	DropTables []string
+	// Name is the Go type and ID of the migration, such as "Migration0002".
+	Name string
+	// Package defaults to "migrations".
+	Package string
+}
+
+type registeredModel struct {
+	columns        func() (string, map[string]reflect.StructField)
+	dumpFixtures   func() (Fixtures, error)
+	insertFixture  func(*sql.Tx, map[string]any) (bool, error)
+	modelType      reflect.Type
+	resetSequence  func(*sql.Tx) error
+	tableCreateSql func(Dialect) (string, error)
+}
+
+var registeredModels []registeredModel
+var registeredModelsMutex sync.Mutex
+
+// RegisterModel adds a model to the set MakeMigration compares to the database schema, and LoadFixtures loads.
+func RegisterModel[T any]() {
+	registeredModelsMutex.Lock()
+	defer registeredModelsMutex.Unlock()
+	modelType := reflect.TypeFor[T]()
+	for _, model := range registeredModels {
+		if model.modelType == modelType {
+			return
+		}
+	}
+	registeredModels = append(registeredModels, registeredModel{
+		columns: func() (string, map[string]reflect.StructField) {
+			weave := Use[T]()
+			fields := make(map[string]reflect.StructField, len(weave.Fields))
+			for column, descriptor := range weave.Descriptors {
+				if descriptor.Kind != FieldKindOneToMany {
+					fields[column] = weave.Fields[column]
+				}
+			}
+			return weave.Table, fields
+		},
+		dumpFixtures:   dumpTableFixtures[T],
+		insertFixture:  insertFixture[T],
+		modelType:      modelType,
+		resetSequence:  resetFixtureSequence[T],
+		tableCreateSql: tableCreateSql[T],
+	})
+}
+
+// TableCreateSql returns the CREATE TABLE statements of registered models for the default dialect, in foreign key
+// order.
+func TableCreateSql() ([]string, error) {
+	if defaultDialect == nil {
+		return nil, errors.New("trance: no dialect registered. Use trance.SetDialect(dialect trance.Dialect) to register a default for SQL queries")
+	}
+	registeredModelsMutex.Lock()
+	models := sortModelsByForeignKeys(slices.Clone(registeredModels))
+	registeredModelsMutex.Unlock()
+
+	statements := make([]string, 0, len(models))
+	for _, model := range models {
+		statement, err := model.tableCreateSql(defaultDialect)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("trance: failed to build table for model '%s'", model.modelType), err)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+func tableCreateSql[T any](dialect Dialect) (string, error) {
+	query := Query[T]().Dialect(dialect)
+	query.configure()
+	return dialect.BuildTableCreate(query.Config, TableCreateConfig{})
+}
+
+type migrationStep struct {
+	down string
+	up   string
+}
+
+type migrationGenerator struct {
+	dropTables []string
+	imports    map[string]string
+	inspector  SchemaInspector
+	name       string
+	snapshots  map[string]SchemaTable
+}
+
+// MakeMigration compares registered models to the schema of the database and returns the source of a Go file
+// declaring a Migration with the TableCreate, TableColumnAdd, TableColumnAlter, TableColumnDrop, and TableDrop calls
+// needed to match. Tables without a registered model are only dropped when listed in DropTables. Down steps use snapshot
+// structs of the current schema where models no longer describe it. Only column types and nullability are compared, so
+// changes to column defaults, indexes, and unique or check constraints declared on models need steps written by hand.
+// It returns an empty string when there are no changes.
+func MakeMigration(config MakeMigrationConfig) (string, error) {
+	db, inspector, err := useSchemaInspector()
+	if err != nil {
+		return "", err
+	}
+	if config.Name == "" {
+		return "", errors.New("trance: migration name is required")
+	}
+	if config.Package == "" {
+		config.Package = "migrations"
+	}
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tables, err := inspector.InspectSchema(ctx, db)
+	if err != nil {
+		return "", errors.Join(errors.New("trance: failed to inspect schema"), err)
+	}
+	registeredModelsMutex.Lock()
+	models := slices.Clone(registeredModels)
+	registeredModelsMutex.Unlock()
+
+	generator := &migrationGenerator{
+		dropTables: config.DropTables,
+		imports:    map[string]string{"github.com/evantbyrne/trance": "trance"},
+		inspector:  inspector,
+		name:       config.Name,
+		snapshots:  make(map[string]SchemaTable),
+	}
+	steps, err := generator.diff(models, tables)
+	if err != nil {
+		return "", err
+	}
+	if len(steps) == 0 {
+		return "", nil
+	}
+	return generator.source(config.Package, steps)
+}
+
+func (generator *migrationGenerator) diff(models []registeredModel, tables []SchemaTable) ([]migrationStep, error) {
+	schemaTables := make(map[string]SchemaTable, len(tables))
+	for _, table := range tables {
+		schemaTables[table.Name] = table
+	}
+	modelTables := make(map[string]bool, len(models))
+	ignoredTable := Use[MigrationLogs]().Table
+
+	creates := make([]registeredModel, 0)
+	alters := make([]migrationStep, 0)
+	for _, model := range models {
+		table, fields := model.columns()
+		modelTables[table] = true
+		modelExpr, err := generator.modelExpr(model.modelType)
+		if err != nil {
+			return nil, err
+		}
+		schemaTable, exists := schemaTables[table]
+		if !exists {
+			creates = append(creates, model)
+			continue
+		}
+
+		columns := maps.Keys(fields)
+		sort.Strings(columns)
+		for _, column := range columns {
+			modelColumn, err := generator.inspector.SchemaColumn(column, fields[column])
+			if err != nil {
+				return nil, err
+			}
+			schemaColumn, exists := schemaTable.Column(column)
+			if !exists {
+				alters = append(alters, migrationStep{
+					down: fmt.Sprintf("trance.MigrationQuery[%s]().TableColumnDrop(%s)", modelExpr, strconv.Quote(column)),
+					up:   fmt.Sprintf("trance.MigrationQuery[%s]().TableColumnAdd(%s)", modelExpr, strconv.Quote(column)),
+				})
+			} else if !schemaColumn.Primary && (!strings.EqualFold(modelColumn.Type, schemaColumn.Type) || modelColumn.Nullable != schemaColumn.Nullable) {
+				alters = append(alters, migrationStep{
+					down: fmt.Sprintf("trance.MigrationQuery[%s]().Table(%s).TableColumnAlter(%s)", generator.snapshot(schemaTable), strconv.Quote(table), strconv.Quote(column)),
+					up:   fmt.Sprintf("trance.MigrationQuery[%s]().TableColumnAlter(%s)", modelExpr, strconv.Quote(column)),
+				})
+			}
+		}
+		for _, schemaColumn := range schemaTable.Columns {
+			if _, exists := fields[schemaColumn.Name]; !exists {
+				alters = append(alters, migrationStep{
+					down: fmt.Sprintf("trance.MigrationQuery[%s]().Table(%s).TableColumnAdd(%s)", generator.snapshot(schemaTable), strconv.Quote(table), strconv.Quote(schemaColumn.Name)),
+					up:   fmt.Sprintf("trance.MigrationQuery[%s]().TableColumnDrop(%s)", modelExpr, strconv.Quote(schemaColumn.Name)),
+				})
+			}
+		}
+	}
+
+	steps := make([]migrationStep, 0)
+	for _, model := range sortModelsByForeignKeys(creates) {
+		modelExpr, err := generator.modelExpr(model.modelType)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, migrationStep{
+			down: fmt.Sprintf("trance.MigrationQuery[%s]().TableDrop()", modelExpr),
+			up:   fmt.Sprintf("trance.MigrationQuery[%s]().TableCreate()", modelExpr),
+		})
+	}
+	steps = append(steps, alters...)
+	for i := len(tables) - 1; i > -1; i-- {
+		table := tables[i]
+		if modelTables[table.Name] || table.Name == ignoredTable || !slices.Contains(generator.dropTables, table.Name) {
+			continue
+		}
+		snapshot := generator.snapshot(table)
+		steps = append(steps, migrationStep{
+			down: fmt.Sprintf("trance.MigrationQuery[%s]().Table(%s).TableCreate()", snapshot, strconv.Quote(table.Name)),
+			up:   fmt.Sprintf("trance.MigrationQuery[%s]().Table(%s).TableDrop()", snapshot, strconv.Quote(table.Name)),
+		})
+	}
+	return steps, nil
+}
+
+// modelExpr returns the Go expression of a model type, and imports its package.
+func (generator *migrationGenerator) modelExpr(modelType reflect.Type) (string, error) {
+	if modelType.Name() == "" || modelType.PkgPath() == "" || strings.Contains(modelType.Name(), "[") || !token.IsExported(modelType.Name()) {
+		return "", fmt.Errorf("trance: model '%s' must be an exported named type to generate migrations", modelType)
+	}
+	alias, ok := generator.imports[modelType.PkgPath()]
+	if !ok {
+		alias = path.Base(modelType.PkgPath())
+		for i := 2; slices.Contains(maps.Values(generator.imports), alias); i++ {
+			alias = fmt.Sprint(path.Base(modelType.PkgPath()), i)
+		}
+		generator.imports[modelType.PkgPath()] = alias
+	}
+	return alias + "." + modelType.Name(), nil
+}
+
+// snapshot returns the name of a struct describing a table as it currently exists.
+func (generator *migrationGenerator) snapshot(table SchemaTable) string {
+	name := strings.ToLower(generator.name[:1]) + generator.name[1:] + goFieldName(table.Name)
+	generator.snapshots[name] = table
+	return name
+}
+
+func (generator *migrationGenerator) source(packageName string, steps []migrationStep) (string, error) {
+	var source strings.Builder
+	fmt.Fprintf(&source, "package %s\n\nimport (\n", packageName)
+	importPaths := maps.Keys(generator.imports)
+	sort.Strings(importPaths)
+	for _, importPath := range importPaths {
+		if alias := generator.imports[importPath]; alias != path.Base(importPath) {
+			fmt.Fprintf(&source, "\t%s %s\n", alias, strconv.Quote(importPath))
+		} else {
+			fmt.Fprintf(&source, "\t%s\n", strconv.Quote(importPath))
+		}
+	}
+	source.WriteString(")\n\n")
+
+	fmt.Fprintf(&source, "// %s was generated by trance.MakeMigration.\ntype %s struct{}\n\n", generator.name, generator.name)
+	fmt.Fprintf(&source, "func (migration %s) MigrationId() string {\n\treturn %s\n}\n\n", generator.name, strconv.Quote(generator.name))
+
+	snapshotNames := maps.Keys(generator.snapshots)
+	sort.Strings(snapshotNames)
+	for _, name := range snapshotNames {
+		table := generator.snapshots[name]
+		fmt.Fprintf(&source, "// %s is a snapshot of table %s before the migration.\ntype %s struct {\n", name, strconv.Quote(table.Name), name)
+		for _, column := range table.Columns {
+			fmt.Fprintf(&source, "\t%s string `@:%s @type:%s`\n", goFieldName(column.Name), strconv.Quote(column.Name), strconv.Quote(snapshotColumnType(column)))
+		}
+		source.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&source, "func (migration %s) Up() error {\n", generator.name)
+	for _, step := range steps {
+		fmt.Fprintf(&source, "\tif err := %s.Error; err != nil {\n\t\treturn err\n\t}\n", step.up)
+	}
+	source.WriteString("\treturn nil\n}\n\n")
+
+	fmt.Fprintf(&source, "func (migration %s) Down() error {\n", generator.name)
+	for i := len(steps) - 1; i > -1; i-- {
+		fmt.Fprintf(&source, "\tif err := %s.Error; err != nil {\n\t\treturn err\n\t}\n", steps[i].down)
+	}
+	source.WriteString("\treturn nil\n}\n")
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return "", errors.Join(errors.New("trance: failed to format migration"), err)
+	}
+	return string(formatted), nil
+}
+
+// snapshotColumnType returns the `@type` field tag that recreates an inspected column.
+func snapshotColumnType(column SchemaColumn) string {
+	columnType := column.Type
+	if column.Primary {
+		columnType += " PRIMARY KEY"
+	}
+	if column.Nullable {
+		columnType += " NULL"
+	} else {
+		columnType += " NOT NULL"
+	}
+	if column.Default != "" {
+		columnType += " DEFAULT " + column.Default
+	}
+	return columnType
+}
+
+// sortModelsByForeignKeys orders models so tables are created after the tables their foreign keys reference.
+func sortModelsByForeignKeys(models []registeredModel) []registeredModel {
+	tables := make(map[string]bool, len(models))
+	for _, model := range models {
+		table, _ := model.columns()
+		tables[table] = true
+	}
+
+	sorted := make([]registeredModel, 0, len(models))
+	created := make(map[string]bool, len(models))
+	for len(sorted) < len(models) {
+		progress := false
+		for _, model := range models {
+			table, fields := model.columns()
+			if created[table] {
+				continue
+			}
+			ready := true
+			for _, field := range fields {
+				if referencedTable, _, ok := ForeignKeyTarget(field); ok && referencedTable != table && tables[referencedTable] && !created[referencedTable] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				created[table] = true
+				sorted = append(sorted, model)
+				progress = true
+			}
+		}
+		if !progress {
+			// Circular foreign keys keep registration order.
+			for _, model := range models {
+				if table, _ := model.columns(); !created[table] {
+					created[table] = true
+					sorted = append(sorted, model)
+				}
+			}
+		}
+	}
+	return sorted
+}
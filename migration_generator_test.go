@@ -0,0 +1,202 @@
+package trance
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type testInspectorDialect struct {
+	testDialect
+	tables []SchemaTable
+}
+
+func (dialect testInspectorDialect) InspectSchema(context.Context, *sql.DB) ([]SchemaTable, error) {
+	return dialect.tables, nil
+}
+
+func (dialect testInspectorDialect) SchemaColumn(column string, field reflect.StructField) (SchemaColumn, error) {
+	schemaColumn := SchemaColumn{Name: column, Primary: field.Tag.Get("@primary") == "true", Type: "BIGINT"}
+	if nullableType, ok := NullableType(field.Type); ok {
+		schemaColumn.Nullable = true
+		field.Type = nullableType
+	}
+	if field.Type.Kind() == reflect.String {
+		schemaColumn.Type = "TEXT"
+	}
+	return schemaColumn, nil
+}
+
+type TestMigrationAccount struct {
+	Group    ForeignKey[TestMigrationGroup] `@:"group_id"`
+	Id       int64                          `@:"id" @primary:"true"`
+	Name     string                         `@:"name"`
+	Nickname *string                        `@:"nickname"`
+}
+
+type TestMigrationGroup struct {
+	Id   int64  `@:"id" @primary:"true"`
+	Name string `@:"name"`
+}
+
+type TestMigrationPost struct {
+	Group ForeignKey[TestMigrationGroup] `@:"group_id"`
+	Id    int64                          `@:"id" @primary:"true"`
+}
+
+func TestMakeMigration(t *testing.T) {
+	registered := registeredModels
+	defer func() {
+		defaultDialect = nil
+		registeredModels = registered
+		PurgeWeaves()
+	}()
+	registeredModels = nil
+	RegisterModel[TestMigrationPost]()
+	RegisterModel[TestMigrationAccount]()
+	RegisterModel[TestMigrationGroup]()
+	RegisterModel[TestMigrationGroup]()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	SetDialect(testInspectorDialect{tables: []SchemaTable{
+		{Name: "migrationlogs", Columns: []SchemaColumn{{Name: "id", Primary: true, Type: "BIGINT"}}},
+		{Name: "orphan", Columns: []SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGSERIAL"},
+			{Default: "'x'", Name: "code", Nullable: true, Type: "VARCHAR(10)"},
+		}},
+		{Name: "testmigrationaccount", Columns: []SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGINT"},
+			{Name: "name", Nullable: true, Type: "TEXT"},
+			{Name: "legacy", Type: "TEXT"},
+		}},
+	}})
+
+	expected := `package migrations
+
+import (
+	"github.com/evantbyrne/trance"
+)
+
+// Migration0002 was generated by trance.MakeMigration.
+type Migration0002 struct{}
+
+func (migration Migration0002) MigrationId() string {
+	return "Migration0002"
+}
+
+// migration0002Orphan is a snapshot of table "orphan" before the migration.
+type migration0002Orphan struct {
+	Id   string ` + "`" + `@:"id" @type:"BIGSERIAL PRIMARY KEY NOT NULL"` + "`" + `
+	Code string ` + "`" + `@:"code" @type:"VARCHAR(10) NULL DEFAULT 'x'"` + "`" + `
+}
+
+// migration0002Testmigrationaccount is a snapshot of table "testmigrationaccount" before the migration.
+type migration0002Testmigrationaccount struct {
+	Id     string ` + "`" + `@:"id" @type:"BIGINT PRIMARY KEY NOT NULL"` + "`" + `
+	Name   string ` + "`" + `@:"name" @type:"TEXT NULL"` + "`" + `
+	Legacy string ` + "`" + `@:"legacy" @type:"TEXT NOT NULL"` + "`" + `
+}
+
+func (migration Migration0002) Up() error {
+	if err := trance.MigrationQuery[trance.TestMigrationGroup]().TableCreate().Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationPost]().TableCreate().Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnAdd("group_id").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnAlter("name").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnAdd("nickname").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnDrop("legacy").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[migration0002Orphan]().Table("orphan").TableDrop().Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (migration Migration0002) Down() error {
+	if err := trance.MigrationQuery[migration0002Orphan]().Table("orphan").TableCreate().Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[migration0002Testmigrationaccount]().Table("testmigrationaccount").TableColumnAdd("legacy").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnDrop("nickname").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[migration0002Testmigrationaccount]().Table("testmigrationaccount").TableColumnAlter("name").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationAccount]().TableColumnDrop("group_id").Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationPost]().TableDrop().Error; err != nil {
+		return err
+	}
+	if err := trance.MigrationQuery[trance.TestMigrationGroup]().TableDrop().Error; err != nil {
+		return err
+	}
+	return nil
+}
+`
+	source, err := MakeMigration(MakeMigrationConfig{DropTables: []string{"orphan"}, Name: "Migration0002"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if source != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, source)
+	}
+
+	registeredModels = nil
+	RegisterModel[TestMigrationGroup]()
+	SetDialect(testInspectorDialect{tables: []SchemaTable{
+		{Name: "orphan", Columns: []SchemaColumn{{Name: "id", Primary: true, Type: "BIGSERIAL"}}},
+		{Name: "testmigrationgroup", Columns: []SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGINT"},
+			{Name: "name", Type: "TEXT"},
+		}},
+	}})
+	source, err = MakeMigration(MakeMigrationConfig{Name: "Migration0003"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if source != "" {
+		t.Errorf("Expected no migration, got '%s'", source)
+	}
+
+	SetDialect(testDialect{})
+	if _, err := MakeMigration(MakeMigrationConfig{Name: "Migration0003"}); err == nil {
+		t.Error("Expected error for dialect without schema inspection")
+	}
+}
+
+func TestGoFieldName(t *testing.T) {
+	for column, expected := range map[string]string{
+		"group_id":  "GroupId",
+		"name":      "Name",
+		"2fa-code":  "Column2faCode",
+		"createdAt": "CreatedAt",
+		"__":        "Column",
+	} {
+		if actual := goFieldName(column); actual != expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", expected, column, actual)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package trance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type testMigrationDialect struct {
+	testDialect
+	transactionalDdl bool
+}
+
+func (dialect testMigrationDialect) BuildInsert(config QueryConfig, _ map[string]any, _ ...string) (string, []any, error) {
+	return fmt.Sprintf("INSERT|%s|", config.Table), nil, nil
+}
+
+func (dialect testMigrationDialect) BuildTableCreate(config QueryConfig, _ TableCreateConfig) (string, error) {
+	return fmt.Sprintf("CREATE|%s|", config.Table), nil
+}
+
+func (dialect testMigrationDialect) BuildTableDrop(config QueryConfig, _ TableDropConfig) (string, error) {
+	return fmt.Sprintf("DROP|%s|", config.Table), nil
+}
+
+func (dialect testMigrationDialect) LockMigrations(ctx context.Context, db *sql.DB) (func() error, error) {
+	if _, err := db.ExecContext(ctx, "LOCK"); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := db.Exec("UNLOCK")
+		return err
+	}, nil
+}
+
+func (dialect testMigrationDialect) TransactionalDdl() bool {
+	return dialect.transactionalDdl
+}
+
+type testMigrationModel struct {
+	Id int64 `@:"id" @primary:"true"`
+}
+
+type testMigrationCreate struct{}
+
+func (m testMigrationCreate) Up() error {
+	return MigrationQuery[testMigrationModel]().TableCreate().Error
+}
+
+func (m testMigrationCreate) Down() error {
+	return MigrationQuery[testMigrationModel]().TableDrop().Error
+}
+
+// testMigrationQuery uses Query instead of MigrationQuery, so it runs outside of the migration transaction.
+type testMigrationQuery struct{}
+
+func (m testMigrationQuery) Up() error {
+	return Query[testMigrationModel]().TableCreate().Error
+}
+
+func (m testMigrationQuery) Down() error {
+	return Query[testMigrationModel]().TableDrop().Error
+}
+
+type testMigrationFail struct{}
+
+func (m testMigrationFail) Up() error {
+	if MigrationTransaction() == nil || MigrationQuery[testMigrationModel]().Config.Transaction != MigrationTransaction() || Query[testMigrationModel]().Config.Transaction != nil {
+		return errors.New("expected migration transaction")
+	}
+	return errors.New("failed")
+}
+
+func (m testMigrationFail) Down() error {
+	return errors.New("failed")
+}
+
+type testMigrationPanic struct{}
+
+func (m testMigrationPanic) Up() error {
+	panic("failed")
+}
+
+func (m testMigrationPanic) Down() error {
+	return nil
+}
+
+func TestMigrate(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	logColumns := []string{"created_at", "direction", "id", "migration_type"}
+	expectSetup := func(rows *sqlmock.Rows) {
+		mock.ExpectExec("LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(rows)
+	}
+
+	t.Run("transactional", func(t *testing.T) {
+		SetDialect(testMigrationDialect{transactionalDdl: true})
+		expectSetup(sqlmock.NewRows(logColumns))
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		logs, err := MigrateUp([]Migration{testMigrationCreate{}, testMigrationQuery{}, testMigrationFail{}})
+		if err == nil || err.Error() != "trance: migration trance.testMigrationFail: failed\nfailed" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if len(logs) != 3 {
+			t.Errorf("Unexpected logs %#v", logs)
+		}
+		if MigrationTransaction() != nil {
+			t.Error("Expected migration transaction to be cleared")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		SetDialect(testMigrationDialect{transactionalDdl: true})
+		expectSetup(sqlmock.NewRows(logColumns))
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			MigrateUp([]Migration{testMigrationPanic{}})
+		}()
+		if MigrationTransaction() != nil {
+			t.Error("Expected migration transaction to be cleared")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("non-transactional", func(t *testing.T) {
+		SetDialect(testMigrationDialect{})
+		expectSetup(sqlmock.NewRows(logColumns).AddRow(time.Now(), "up", 1, "trance.testMigrationCreate"))
+		mock.ExpectExec("DROP|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		logs, err := MigrateDownSteps([]Migration{testMigrationCreate{}, testMigrationFail{}}, 2)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if len(logs) != 1 || logs[0] != "Migrating down to trance.testMigrationCreate..." {
+			t.Errorf("Unexpected logs %#v", logs)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+type testMigrationIdentified struct{}
+
+func (m testMigrationIdentified) MigrationId() string {
+	return "0003_identified"
+}
+
+func (m testMigrationIdentified) Up() error {
+	return nil
+}
+
+func (m testMigrationIdentified) Down() error {
+	return nil
+}
+
+func TestMigrationStatus(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testMigrationDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	migrations := []Migration{testMigrationCreate{}, testMigrationFail{}, testMigrationIdentified{}}
+	expectLogs := func() {
+		mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(sqlmock.NewRows([]string{"created_at", "direction", "id", "migration_type"}).
+			AddRow(appliedAt, "up", 1, "trance.testMigrationCreate").
+			AddRow(appliedAt, "up", 2, "trance.testMigrationRemoved").
+			AddRow(appliedAt, "up", 3, "trance.testMigrationFail").
+			AddRow(appliedAt, "down", 4, "trance.testMigrationFail").
+			AddRow(appliedAt, "up", 5, "0003_identified"))
+	}
+
+	expectLogs()
+	statuses, err := MigrationStatus(migrations)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []MigrationStatusEntry{
+		{AppliedAt: appliedAt, Id: "trance.testMigrationCreate", State: MigrationApplied},
+		{Id: "trance.testMigrationFail", State: MigrationPending},
+		{AppliedAt: appliedAt, Id: "0003_identified", State: MigrationApplied},
+		{AppliedAt: appliedAt, Id: "trance.testMigrationRemoved", State: MigrationUnknown},
+	}
+	if !reflect.DeepEqual(expected, statuses) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, statuses)
+	}
+
+	mock.ExpectExec("LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLogs()
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := MigrateUp(migrations); err == nil || err.Error() != "trance: applied migrations not found: trance.testMigrationRemoved" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := MigrationStatus([]Migration{testMigrationCreate{}, testMigrationCreate{}}); err == nil {
+		t.Error("Expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrateTo(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testMigrationDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	migrations := []Migration{testMigrationCreate{}, testMigrationIdentified{}, testMigrationFail{}}
+	expectLogs := func(rows *sqlmock.Rows) {
+		mock.ExpectExec("LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(rows)
+	}
+	columns := []string{"created_at", "direction", "id", "migration_type"}
+
+	expectLogs(sqlmock.NewRows(columns))
+	mock.ExpectExec("CREATE|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	logs, err := MigrateTo(migrations, "0003_identified")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"Migrating up to trance.testMigrationCreate...", "Migrating up to 0003_identified..."}
+	if !reflect.DeepEqual(expected, logs) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, logs)
+	}
+
+	expectLogs(sqlmock.NewRows(columns).
+		AddRow(time.Now(), "up", 1, "trance.testMigrationCreate").
+		AddRow(time.Now(), "up", 2, "0003_identified"))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	logs, err = MigrateDownSteps(migrations, 1)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected = []string{"Migrating down to 0003_identified..."}
+	if !reflect.DeepEqual(expected, logs) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, logs)
+	}
+
+	expectLogs(sqlmock.NewRows(columns).
+		AddRow(time.Now(), "up", 1, "trance.testMigrationCreate").
+		AddRow(time.Now(), "up", 2, "0003_identified"))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("DROP|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	logs, err = MigrateDown(migrations)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected = []string{"Migrating down to 0003_identified...", "Migrating down to trance.testMigrationCreate..."}
+	if !reflect.DeepEqual(expected, logs) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, logs)
+	}
+
+	expectLogs(sqlmock.NewRows(columns))
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := MigrateTo(migrations, "missing"); err == nil || err.Error() != "trance: migration 'missing' not found" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
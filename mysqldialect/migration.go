@@ -0,0 +1,43 @@
+package mysqldialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/evantbyrne/trance"
+)
+
+// migrationLockName names the lock held while migrations run.
+const migrationLockName = "trance_migrations"
+
+// LockMigrations takes a named lock with GET_LOCK on a dedicated connection of the pool, which is held until the
+// returned function is called.
+func (dialect MysqlDialect) LockMigrations(ctx context.Context, db *sql.DB) (func() error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", migrationLockName).Scan(&acquired); err != nil {
+		return nil, errors.Join(err, conn.Close())
+	}
+	if acquired.Int64 != 1 {
+		return nil, errors.Join(errors.New("trance: GET_LOCK failed"), conn.Close())
+	}
+	return func() error {
+		var released sql.NullInt64
+		err := conn.QueryRowContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName).Scan(&released)
+		return errors.Join(err, conn.Close())
+	}, nil
+}
+
+// TransactionalDdl is false, because MySQL implicitly commits before schema changes.
+func (dialect MysqlDialect) TransactionalDdl() bool {
+	return false
+}
+
+// SplitStatements splits SQL files into statements, allowing backslash escapes and # comments.
+func (dialect MysqlDialect) SplitStatements(source string) []string {
+	return trance.SplitStatements(source, trance.SplitStatementsConfig{BackslashEscapes: true, HashComments: true})
+}
@@ -2,6 +2,7 @@ package mysqldialect
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -67,6 +68,49 @@ func (dialect MysqlDialect) BuildDelete(config trance.QueryConfig) (string, []an
 	return queryString.String(), args, nil
 }
 
+func (dialect MysqlDialect) BuildIndexCreate(config trance.QueryConfig, index trance.Index, indexCreateConfig trance.IndexCreateConfig) (string, error) {
+	if indexCreateConfig.IfNotExists {
+		return "", errors.New("trance: MySQL does not support IF NOT EXISTS on CREATE INDEX")
+	}
+	if index.Where != "" {
+		return "", fmt.Errorf("trance: MySQL does not support partial indexes, but index '%s' has a WHERE clause", index.Name)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("CREATE ")
+	if index.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX ")
+	sql.WriteString(dialect.QuoteIdentifier(index.Name))
+	sql.WriteString(" ON ")
+
+	// TABLE
+	from, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+	sql.WriteString(from)
+
+	sql.WriteString(" (")
+	sql.WriteString(dialect.buildIndexColumns(index.Columns))
+	sql.WriteString(")")
+	return sql.String(), nil
+}
+
+func (dialect MysqlDialect) BuildIndexDrop(config trance.QueryConfig, name string, indexDropConfig trance.IndexDropConfig) (string, error) {
+	if indexDropConfig.IfExists {
+		return "", errors.New("trance: MySQL does not support IF EXISTS on DROP INDEX")
+	}
+
+	// TABLE
+	from, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DROP INDEX %s ON %s", dialect.QuoteIdentifier(name), from), nil
+}
+
 func (dialect MysqlDialect) BuildInsert(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := make([]any, 0)
 	var queryString strings.Builder
@@ -111,6 +155,65 @@ func (dialect MysqlDialect) BuildInsert(config trance.QueryConfig, rowMap map[st
 	return queryString.String(), args, nil
 }
 
+// buildConstraints returns table constraints to append to the column definitions of CREATE TABLE.
+func (dialect MysqlDialect) buildConstraints(config trance.QueryConfig) string {
+	var sql strings.Builder
+	for _, constraint := range config.Constraints {
+		sql.WriteString(",\n\tCONSTRAINT ")
+		sql.WriteString(dialect.QuoteIdentifier(constraint.Name))
+		if constraint.Check != "" {
+			sql.WriteString(" CHECK (")
+			sql.WriteString(constraint.Check)
+			sql.WriteString(")")
+		} else {
+			sql.WriteString(" UNIQUE (")
+			sql.WriteString(dialect.buildIndexColumns(constraint.Columns))
+			sql.WriteString(")")
+		}
+	}
+	return sql.String()
+}
+
+// buildForeignKeyConstraint returns the named FOREIGN KEY constraint of a column. CREATE TABLE, ADD COLUMN, and
+// BuildTableForeignKeyAdd all declare it this way, so BuildTableForeignKeyDrop can drop it by name.
+func (dialect MysqlDialect) buildForeignKeyConstraint(config trance.QueryConfig, column string) (string, error) {
+	reference, err := dialect.buildForeignKeyReference(config, column)
+	if err != nil {
+		return "", err
+	}
+	name := trance.ForeignKeyName(fmt.Sprint(config.Table), column)
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) %s", dialect.QuoteIdentifier(name), dialect.QuoteIdentifier(column), reference), nil
+}
+
+func (dialect MysqlDialect) buildForeignKeyReference(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	referencedTable, referencedColumn, ok := trance.ForeignKeyTarget(field)
+	if !ok {
+		return "", fmt.Errorf("trance: column '%s' on model for table '%s' is not a foreign key", column, config.Table)
+	}
+	reference := fmt.Sprintf("REFERENCES %s (%s)", dialect.QuoteIdentifier(referencedTable), dialect.QuoteIdentifier(referencedColumn))
+	if tagOnUpdate := field.Tag.Get("@on_update"); tagOnUpdate != "" {
+		// ON UPDATE.
+		reference = fmt.Sprint(reference, " ON UPDATE ", tagOnUpdate)
+	}
+	if tagOnDelete := field.Tag.Get("@on_delete"); tagOnDelete != "" {
+		// ON DELETE.
+		reference = fmt.Sprint(reference, " ON DELETE ", tagOnDelete)
+	}
+	return reference, nil
+}
+
+func (dialect MysqlDialect) buildIndexColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ",")
+}
+
 func (dialect MysqlDialect) buildJoins(config trance.QueryConfig, args []any) (string, []any, error) {
 	var queryPart strings.Builder
 	if len(config.Joins) > 0 {
@@ -253,7 +356,46 @@ func (dialect MysqlDialect) BuildTableColumnAdd(config trance.QueryConfig, colum
 		return "", err
 	}
 
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", from, dialect.QuoteIdentifier(column), columnType), nil
+	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", from, dialect.QuoteIdentifier(column), columnType)
+	if _, _, ok := trance.ForeignKeyTarget(field); ok {
+		constraint, err := dialect.buildForeignKeyConstraint(config, column)
+		if err != nil {
+			return "", err
+		}
+		sql += ", ADD " + constraint
+	}
+	return sql, nil
+}
+
+func (dialect MysqlDialect) BuildTableColumnAlter(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	if field.Tag.Get("@primary") == "true" {
+		return "", fmt.Errorf("trance: altering primary key column '%s' on table '%s' is not supported", column, config.Table)
+	}
+
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return "", err
+	}
+	columnType, columnNull := splitColumnType(columnType)
+
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	// MODIFY COLUMN replaces the column definition, so foreign keys and unique indexes are left as they are.
+	var sql strings.Builder
+	fmt.Fprintf(&sql, "ALTER TABLE %s MODIFY COLUMN %s %s%s", table, dialect.QuoteIdentifier(column), columnType, columnNull)
+	if tagDefault := field.Tag.Get("@default"); tagDefault != "" {
+		sql.WriteString(" DEFAULT ")
+		sql.WriteString(tagDefault)
+	}
+	return sql.String(), nil
 }
 
 func (dialect MysqlDialect) BuildTableColumnDrop(config trance.QueryConfig, column string) (string, error) {
@@ -266,6 +408,16 @@ func (dialect MysqlDialect) BuildTableColumnDrop(config trance.QueryConfig, colu
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", from, dialect.QuoteIdentifier(column)), nil
 }
 
+func (dialect MysqlDialect) BuildTableColumnRename(config trance.QueryConfig, from string, to string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, dialect.QuoteIdentifier(from), dialect.QuoteIdentifier(to)), nil
+}
+
 func (dialect MysqlDialect) BuildTableCreate(config trance.QueryConfig, tableCreateConfig trance.TableCreateConfig) (string, error) {
 	var sql strings.Builder
 	sql.WriteString("CREATE TABLE ")
@@ -297,6 +449,32 @@ func (dialect MysqlDialect) BuildTableCreate(config trance.QueryConfig, tableCre
 		sql.WriteString(" ")
 		sql.WriteString(columnType)
 	}
+	sql.WriteString(dialect.buildConstraints(config))
+	for _, fieldName := range fieldNames {
+		if _, _, ok := trance.ForeignKeyTarget(config.Fields[fieldName]); ok {
+			constraint, err := dialect.buildForeignKeyConstraint(config, fieldName)
+			if err != nil {
+				return "", err
+			}
+			sql.WriteString(",\n\t")
+			sql.WriteString(constraint)
+		}
+	}
+	for _, index := range config.Indexes {
+		// MySQL declares indexes inline, which keeps CREATE TABLE IF NOT EXISTS to a single statement.
+		if index.Where != "" {
+			return "", fmt.Errorf("trance: MySQL does not support partial indexes, but index '%s' has a WHERE clause", index.Name)
+		}
+		sql.WriteString(",\n\t")
+		if index.Unique {
+			sql.WriteString("UNIQUE ")
+		}
+		sql.WriteString("INDEX ")
+		sql.WriteString(dialect.QuoteIdentifier(index.Name))
+		sql.WriteString(" (")
+		sql.WriteString(dialect.buildIndexColumns(index.Columns))
+		sql.WriteString(")")
+	}
 	sql.WriteString("\n)")
 	return sql.String(), nil
 }
@@ -318,6 +496,42 @@ func (dialect MysqlDialect) BuildTableDrop(config trance.QueryConfig, tableDropC
 	return queryString.String(), nil
 }
 
+func (dialect MysqlDialect) BuildTableForeignKeyAdd(config trance.QueryConfig, column string) (string, error) {
+	constraint, err := dialect.buildForeignKeyConstraint(config, column)
+	if err != nil {
+		return "", err
+	}
+
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", table, constraint), nil
+}
+
+func (dialect MysqlDialect) BuildTableForeignKeyDrop(config trance.QueryConfig, column string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	name := trance.ForeignKeyName(fmt.Sprint(config.Table), column)
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", table, dialect.QuoteIdentifier(name)), nil
+}
+
+func (dialect MysqlDialect) BuildTableRename(config trance.QueryConfig, name string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, dialect.QuoteIdentifier(name)), nil
+}
+
 func (dialect MysqlDialect) BuildUpdate(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := append([]any(nil), config.Params...)
 	var queryString strings.Builder
@@ -413,6 +627,17 @@ func (dialect MysqlDialect) ColumnType(field reflect.StructField) (string, error
 		return tagType, nil
 	}
 
+	if nullableType, ok := trance.NullableType(field.Type); ok && field.Tag.Get("@primary") != "true" {
+		// Pointers and sql.Null[T].
+		nullableField := field
+		nullableField.Type = nullableType
+		columnType, err := dialect.ColumnType(nullableField)
+		if err != nil {
+			return "", err
+		}
+		return strings.Replace(columnType, " NOT NULL", " NULL", 1), nil
+	}
+
 	fieldInstance := reflect.Indirect(reflect.New(field.Type)).Interface()
 	var columnNull string
 	var columnPrimary string
@@ -440,6 +665,30 @@ func (dialect MysqlDialect) ColumnType(field reflect.StructField) (string, error
 		}
 	}
 
+	if sqlType, ok := trance.RegisteredSqlType(dialect, field.Type); columnType == "" && ok {
+		// Types added with trance.RegisterType.
+		columnNull = " NOT NULL"
+		columnType = sqlType
+	}
+
+	if enumValues, ok := trance.EnumValues(field.Type); columnType == "" && ok && len(enumValues) > 0 {
+		quotedValues := make([]string, len(enumValues))
+		for i, enumValue := range enumValues {
+			quotedValues[i] = quoteString(enumValue)
+		}
+		columnNull = " NOT NULL"
+		columnType = fmt.Sprintf("ENUM(%s)", strings.Join(quotedValues, ","))
+	}
+
+	if columnType == "" && trance.IsJsonField(field) {
+		columnNull = " NOT NULL"
+		columnType = "JSON"
+	}
+
+	if columnType == "" && field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+		return "", fmt.Errorf("trance: Unsupported column type: %s. Arrays are only supported by Postgres. Use the '@json' field tag to store slices as JSON", field.Type)
+	}
+
 	if columnType == "" {
 		switch fieldInstance.(type) {
 		case bool:
@@ -506,6 +755,17 @@ func (dialect MysqlDialect) ColumnType(field reflect.StructField) (string, error
 				columnType = "TEXT"
 			}
 
+		case trance.Decimal:
+			columnNull = " NOT NULL"
+			columnType = "DECIMAL"
+			if tagPrecision := field.Tag.Get("@precision"); tagPrecision != "" {
+				if tagScale := field.Tag.Get("@scale"); tagScale != "" {
+					columnType = fmt.Sprintf("DECIMAL(%s,%s)", tagPrecision, tagScale)
+				} else {
+					columnType = fmt.Sprintf("DECIMAL(%s)", tagPrecision)
+				}
+			}
+
 		case time.Time:
 			columnNull = " NOT NULL"
 			columnType = "DATETIME"
@@ -521,7 +781,6 @@ func (dialect MysqlDialect) ColumnType(field reflect.StructField) (string, error
 				subModelQ := fv.Addr().MethodByName("Weave").Call(nil)
 				subFields := reflect.Indirect(subModelQ[0]).FieldByName("Fields").Interface().(map[string]reflect.StructField)
 				subPrimaryColumn := reflect.Indirect(subModelQ[0]).FieldByName("PrimaryColumn").Interface().(string)
-				subTable := reflect.Indirect(subModelQ[0]).FieldByName("Table").Interface().(string)
 				columnTypeTemp, err := dialect.ColumnType(subFields[subPrimaryColumn])
 				if err != nil {
 					return "", err
@@ -529,21 +788,12 @@ func (dialect MysqlDialect) ColumnType(field reflect.StructField) (string, error
 				columnType = strings.SplitN(columnTypeTemp, " ", 2)[0]
 				columnType = strings.Replace(columnType, " AUTO_INCREMENT", "", 1)
 
+				// InnoDB ignores inline REFERENCES, so BuildTableCreate and BuildTableColumnAdd declare a named
+				// FOREIGN KEY constraint instead.
 				columnNull = " NOT NULL"
 				if strings.HasPrefix(field.Type.String(), "trance.NullForeignKey[") {
 					columnNull = " NULL"
 				}
-				columnNull = fmt.Sprintf("%s REFERENCES %s (%s)", columnNull, dialect.QuoteIdentifier(subTable), dialect.QuoteIdentifier(subPrimaryColumn))
-
-				if tagOnUpdate := field.Tag.Get("@on_update"); tagOnUpdate != "" {
-					// ON UPDATE.
-					columnNull = fmt.Sprint(columnNull, " ON UPDATE ", tagOnUpdate)
-				}
-
-				if tagOnDelete := field.Tag.Get("@on_delete"); tagOnDelete != "" {
-					// ON DELETE.
-					columnNull = fmt.Sprint(columnNull, " ON DELETE ", tagOnDelete)
-				}
 			}
 		}
 	}
@@ -583,3 +833,19 @@ func (dialect MysqlDialect) QuoteIdentifier(identifier string) string {
 func QuoteIdentifier(identifier string) string {
 	return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
 }
+
+// quoteString quotes a string literal for use in DDL statements, which do not accept parameters.
+func quoteString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(value) + "'"
+}
+
+// splitColumnType splits a column definition returned by ColumnType into its type and NULL constraint.
+func splitColumnType(columnType string) (string, string) {
+	if i := strings.Index(columnType, " NOT NULL"); i > -1 {
+		return columnType[:i], " NOT NULL"
+	}
+	if i := strings.Index(columnType, " NULL"); i > -1 {
+		return columnType[:i], " NULL"
+	}
+	return columnType, ""
+}
@@ -1,11 +1,14 @@
 package mysqldialect
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/evantbyrne/trance"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -124,6 +127,43 @@ func TestBuildDelete(t *testing.T) {
 	}
 }
 
+func TestBuildIndexCreate(t *testing.T) {
+	dialect := MysqlDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "CREATE INDEX `testmodel_a_b_idx` ON `testmodel` (`a`,`b`)"
+	queryString, err := dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a", "b"}, Name: "testmodel_a_b_idx"}, trance.IndexCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a"}, Name: "testmodel_a_idx", Where: "b IS NULL"}, trance.IndexCreateConfig{}); err == nil {
+		t.Error("Expected error for partial index")
+	}
+	if _, err := dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a"}, Name: "testmodel_a_idx"}, trance.IndexCreateConfig{IfNotExists: true}); err == nil {
+		t.Error("Expected error for IF NOT EXISTS")
+	}
+}
+
+func TestBuildIndexDrop(t *testing.T) {
+	dialect := MysqlDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "DROP INDEX `testmodel_a_b_idx` ON `testmodel`"
+	queryString, err := dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{IfExists: true}); err == nil {
+		t.Error("Expected error for IF EXISTS")
+	}
+}
+
 func TestBuildInsert(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -311,6 +351,43 @@ func TestBuildTableColumnAdd(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnAlter(t *testing.T) {
+	type testModel struct {
+		Id    int64   `@:"id" @primary:"true"`
+		Count int64   `@:"test_count"`
+		Value *string `@:"test_value" @default:"''" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := MysqlDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := "ALTER TABLE `testmodel` MODIFY COLUMN `test_value` VARCHAR(100) NULL DEFAULT ''"
+	queryString, err := dialect.BuildTableColumnAlter(config, "test_value")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = "ALTER TABLE `testmodel` MODIFY COLUMN `test_count` BIGINT NOT NULL"
+	queryString, err = dialect.BuildTableColumnAlter(config, "test_count")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableColumnAlter(config, "id"); err == nil {
+		t.Error("Expected error for primary key column")
+	}
+}
+
 func TestBuildTableColumnDrop(t *testing.T) {
 	dialect := MysqlDialect{}
 	config := trance.QueryConfig{Table: "testmodel"}
@@ -324,6 +401,19 @@ func TestBuildTableColumnDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnRename(t *testing.T) {
+	dialect := MysqlDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "ALTER TABLE `testmodel` RENAME COLUMN `a` TO `b`"
+	queryString, err := dialect.BuildTableColumnRename(config, "a", "b")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildTableCreate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -365,6 +455,47 @@ func TestBuildTableCreate(t *testing.T) {
 	}
 }
 
+func TestBuildTableCreateIndexes(t *testing.T) {
+	type testModel struct {
+		Id      int64  `@:"id" @primary:"true"`
+		GroupId int64  `@:"group_id" @index:"true"`
+		Slug    string `@:"slug" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := MysqlDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Constraints: []trance.Constraint{
+			{Check: "id > 0", Name: "testmodel_check1"},
+			{Columns: []string{"group_id", "slug"}, Name: "testmodel_group_id_slug_key"},
+		},
+		Fields:  weave.Fields,
+		Indexes: weave.Indexes,
+		Table:   "testmodel",
+	}
+	expectedSql := "CREATE TABLE IF NOT EXISTS `testmodel` (\n" +
+		"\t`group_id` BIGINT NOT NULL,\n" +
+		"\t`id` BIGINT PRIMARY KEY NOT NULL AUTO_INCREMENT,\n" +
+		"\t`slug` VARCHAR(100) NOT NULL,\n" +
+		"\tCONSTRAINT `testmodel_check1` CHECK (id > 0),\n" +
+		"\tCONSTRAINT `testmodel_group_id_slug_key` UNIQUE (`group_id`,`slug`),\n" +
+		"\tINDEX `testmodel_group_id_idx` (`group_id`)\n" +
+		")"
+	queryString, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{IfNotExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	config.Indexes = []trance.Index{{Columns: []string{"slug"}, Name: "testmodel_slug_idx", Where: "id > 0"}}
+	if _, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{}); err == nil {
+		t.Error("Expected error for partial index")
+	}
+}
+
 func TestBuildTableDrop(t *testing.T) {
 	dialect := MysqlDialect{}
 	config := trance.QueryConfig{Table: "testmodel"}
@@ -387,6 +518,81 @@ func TestBuildTableDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableForeignKey(t *testing.T) {
+	type testFkInt struct {
+		Id int64 `@:"id" @primary:"true"`
+	}
+
+	type testModel struct {
+		ForeignKey trance.ForeignKey[testFkInt] `@:"test_fk_id" @on_delete:"CASCADE"`
+		Value      int64                        `@:"test_value"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := MysqlDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := "ALTER TABLE `testmodel` ADD CONSTRAINT `testmodel_test_fk_id_fkey` FOREIGN KEY (`test_fk_id`) REFERENCES `testfkint` (`id`) ON DELETE CASCADE"
+	queryString, err := dialect.BuildTableForeignKeyAdd(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableForeignKeyAdd(config, "test_value"); err == nil {
+		t.Error("Expected error for column that isn't a foreign key")
+	}
+
+	expectedSql = "CREATE TABLE `testmodel` (\n" +
+		"\t`test_fk_id` BIGINT NOT NULL,\n" +
+		"\t`test_value` BIGINT NOT NULL,\n" +
+		"\tCONSTRAINT `testmodel_test_fk_id_fkey` FOREIGN KEY (`test_fk_id`) REFERENCES `testfkint` (`id`) ON DELETE CASCADE\n" +
+		")"
+	queryString, err = dialect.BuildTableCreate(config, trance.TableCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = "ALTER TABLE `testmodel` ADD COLUMN `test_fk_id` BIGINT NOT NULL, ADD CONSTRAINT `testmodel_test_fk_id_fkey` FOREIGN KEY (`test_fk_id`) REFERENCES `testfkint` (`id`) ON DELETE CASCADE"
+	queryString, err = dialect.BuildTableColumnAdd(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = "ALTER TABLE `testmodel` DROP FOREIGN KEY `testmodel_test_fk_id_fkey`"
+	queryString, err = dialect.BuildTableForeignKeyDrop(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildTableRename(t *testing.T) {
+	dialect := MysqlDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "ALTER TABLE `testmodel` RENAME TO `renamed`"
+	queryString, err := dialect.BuildTableRename(config, "renamed")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildUpdate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -461,6 +667,50 @@ func TestBuildUpdate(t *testing.T) {
 	}
 }
 
+type testStatus string
+
+func (status testStatus) Values() []string {
+	return []string{"active", "it's archived"}
+}
+
+func TestInspectSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT c.TABLE_NAME").WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "PRIMARY", "UNIQUE", "EXTRA"}).
+		AddRow("accounts", "id", "bigint(20)", "NO", nil, true, false, "auto_increment").
+		AddRow("accounts", "name", "varchar(100)", "YES", "x", false, true, "").
+		AddRow("accounts", "status", "enum('Active','archived')", "NO", nil, false, false, "").
+		AddRow("groups", "created_at", "datetime", "NO", "CURRENT_TIMESTAMP", false, false, "DEFAULT_GENERATED").
+		AddRow("groups", "enabled", "tinyint(1)", "NO", nil, false, false, ""))
+	mock.ExpectQuery("SELECT k.TABLE_NAME").WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME"}).
+		AddRow("groups", "enabled", "accounts", "id"))
+	tables, err := MysqlDialect{}.InspectSchema(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []trance.SchemaTable{
+		{Name: "accounts", Columns: []trance.SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGINT"},
+			{Default: "'x'", Name: "name", Nullable: true, Type: "VARCHAR(100)", Unique: true},
+			{Name: "status", Type: "ENUM('Active','archived')"},
+		}},
+		{Name: "groups", Columns: []trance.SchemaColumn{
+			{Default: "CURRENT_TIMESTAMP", Name: "created_at", Type: "DATETIME"},
+			{ForeignColumn: "id", ForeignTable: "accounts", Name: "enabled", Type: "BOOLEAN"},
+		}},
+	}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, tables)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestColumnType(t *testing.T) {
 	type testFkInt struct {
 		Id int64 `@:"id" @primary:"true"`
@@ -496,6 +746,17 @@ func TestColumnType(t *testing.T) {
 		ForiegnKey     trance.ForeignKey[testFkString]  `@:"test_fk_id" @on_delete:"CASCADE"`
 		ForiegnKeyNull trance.NullForeignKey[testFkInt] `@:"test_fk_null_id" @on_delete:"SET NULL" @on_update:"SET NULL"`
 		Unique         string                           `@:"test_unique" @length:"255" @unique:"true"`
+		Json           trance.JSON[map[string]any]      `@:"test_json"`
+		JsonNull       *map[string]any                  `@:"test_json_null" @json:"true"`
+		JsonTag        []string                         `@:"test_json_tag" @json:"true" @default:"'[]'"`
+		GenericNull    sql.Null[int64]                  `@:"test_generic_null"`
+		PointerText    *string                          `@:"test_pointer_text" @default:"'foo'"`
+		PointerTime    *time.Time                       `@:"test_pointer_time"`
+		Decimal        trance.Decimal                   `@:"test_decimal"`
+		DecimalMoney   trance.Decimal                   `@:"test_decimal_money" @precision:"12" @scale:"2"`
+		DecimalNull    *trance.Decimal                  `@:"test_decimal_null" @precision:"8"`
+		Enum           testStatus                       `@:"test_enum"`
+		EnumNull       *testStatus                      `@:"test_enum_null"`
 	}
 	defer func() {
 		trance.PurgeWeaves()
@@ -525,9 +786,20 @@ func TestColumnType(t *testing.T) {
 		"test_tiny_int":       "TINYINT NOT NULL",
 		"test_varchar":        "VARCHAR(100) NOT NULL",
 		"test_varchar_null":   "VARCHAR(50) NULL",
-		"test_fk_id":          "VARCHAR(100) NOT NULL REFERENCES `testfkstring` (`id`) ON DELETE CASCADE",
-		"test_fk_null_id":     "BIGINT NULL REFERENCES `testfkint` (`id`) ON UPDATE SET NULL ON DELETE SET NULL",
+		"test_fk_id":          "VARCHAR(100) NOT NULL",
+		"test_fk_null_id":     "BIGINT NULL",
 		"test_unique":         "VARCHAR(255) NOT NULL UNIQUE",
+		"test_json":           "JSON NOT NULL",
+		"test_json_null":      "JSON NULL",
+		"test_json_tag":       "JSON NOT NULL DEFAULT '[]'",
+		"test_generic_null":   "BIGINT NULL",
+		"test_pointer_text":   "TEXT NULL DEFAULT 'foo'",
+		"test_pointer_time":   "DATETIME NULL",
+		"test_decimal":        "DECIMAL NOT NULL",
+		"test_decimal_money":  "DECIMAL(12,2) NOT NULL",
+		"test_decimal_null":   "DECIMAL(8) NULL",
+		"test_enum":           `ENUM('active','it''s archived') NOT NULL`,
+		"test_enum_null":      `ENUM('active','it''s archived') NULL`,
 	}
 
 	dialect := MysqlDialect{}
@@ -547,6 +819,44 @@ func TestColumnType(t *testing.T) {
 	}
 }
 
+func TestColumnTypeArray(t *testing.T) {
+	type testModel struct {
+		Tags []string `@:"tags"`
+	}
+	field, _ := reflect.TypeOf(testModel{}).FieldByName("Tags")
+	if _, err := (MysqlDialect{}).ColumnType(field); err == nil {
+		t.Fatal("Expected an error for a slice column")
+	}
+}
+
+func TestColumnTypeRegistered(t *testing.T) {
+	type testNetwork struct {
+		Mask []byte
+	}
+	type testModel struct {
+		Network     testNetwork  `@:"network"`
+		NetworkNull *testNetwork `@:"network_null" @unique:"true"`
+	}
+	trance.RegisterType(trance.ColumnType[testNetwork]{
+		SqlTypes: map[trance.Dialect]string{MysqlDialect{}: "VARBINARY(16)"},
+	})
+
+	expected := map[string]string{
+		"Network":     "VARBINARY(16) NOT NULL",
+		"NetworkNull": "VARBINARY(16) NULL UNIQUE",
+	}
+	for name, expectedType := range expected {
+		field, _ := reflect.TypeOf(testModel{}).FieldByName(name)
+		columnType, err := (MysqlDialect{}).ColumnType(field)
+		if err != nil {
+			t.Fatalf(`dialect.ColumnType() threw error for '%#v': %s`, field, err)
+		}
+		if columnType != expectedType {
+			t.Errorf("Expected '%s', got '%s'", expectedType, columnType)
+		}
+	}
+}
+
 func TestQuoteIdentifier(t *testing.T) {
 	values := map[string]string{
 		"abc":    "`abc`",
@@ -565,3 +875,66 @@ func TestQuoteIdentifier(t *testing.T) {
 		}
 	}
 }
+
+func TestSchemaColumn(t *testing.T) {
+	type testModel struct {
+		Id    int64          `@:"id" @primary:"true"`
+		Name  *string        `@:"name" @default:"'x'" @length:"100"`
+		Total trance.Decimal `@:"total" @precision:"10"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := MysqlDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	expected := []trance.SchemaColumn{
+		{Name: "id", Primary: true, Type: "BIGINT"},
+		{Default: "'x'", Name: "name", Nullable: true, Type: "VARCHAR(100)"},
+		{Name: "total", Type: "DECIMAL(10)"},
+	}
+	for _, expectedColumn := range expected {
+		column, err := dialect.SchemaColumn(expectedColumn.Name, weave.Fields[expectedColumn.Name])
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if column != expectedColumn {
+			t.Errorf("Expected '%#v', got '%#v'", expectedColumn, column)
+		}
+	}
+}
+
+func TestLockMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT GET_LOCK(?, -1)").WithArgs(migrationLockName).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectQuery("SELECT RELEASE_LOCK(?)").WithArgs(migrationLockName).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	unlock, err := MysqlDialect{}.LockMigrations(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	mock.ExpectQuery("SELECT GET_LOCK(?, -1)").WithArgs(migrationLockName).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(nil))
+	if _, err := (MysqlDialect{}).LockMigrations(context.Background(), db); err == nil {
+		t.Error("Expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	source := "# Seed.\nINSERT INTO a (b) VALUES ('it\\'s;');\nINSERT INTO a (b) VALUES (\"x\\\";\")"
+	expected := []string{
+		"# Seed.\nINSERT INTO a (b) VALUES ('it\\'s;')",
+		"INSERT INTO a (b) VALUES (\"x\\\";\")",
+	}
+	if actual := (MysqlDialect{}).SplitStatements(source); !slices.Equal(expected, actual) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,119 @@
+package mysqldialect
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+var mysqlIntegerWidth = regexp.MustCompile(`^(TINYINT|SMALLINT|MEDIUMINT|INT|BIGINT)\(\d+\)`)
+
+// InspectSchema reads tables in the current database from information_schema.
+func (dialect MysqlDialect) InspectSchema(ctx context.Context, db *sql.DB) ([]trance.SchemaTable, error) {
+	rows, err := db.QueryContext(ctx, `SELECT c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT, c.COLUMN_KEY = 'PRI', c.COLUMN_KEY = 'UNI', c.EXTRA
+FROM information_schema.COLUMNS c
+JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]trance.SchemaTable, 0)
+	for rows.Next() {
+		var column trance.SchemaColumn
+		var columnDefault sql.NullString
+		var columnType, extra, isNullable, table string
+		if err := rows.Scan(&table, &column.Name, &columnType, &isNullable, &columnDefault, &column.Primary, &column.Unique, &extra); err != nil {
+			return nil, err
+		}
+		if columnDefault.Valid {
+			if strings.Contains(extra, "DEFAULT_GENERATED") {
+				// Expressions, such as CURRENT_TIMESTAMP.
+				column.Default = columnDefault.String
+			} else {
+				column.Default = quoteString(columnDefault.String)
+			}
+		}
+		column.Nullable = isNullable == "YES"
+		column.Type = inspectedType(columnType)
+		if len(tables) == 0 || tables[len(tables)-1].Name != table {
+			tables = append(tables, trance.SchemaTable{Name: table})
+		}
+		tables[len(tables)-1].Columns = append(tables[len(tables)-1].Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tables, dialect.inspectForeignKeys(ctx, db, tables)
+}
+
+// inspectForeignKeys sets single column foreign keys on inspected columns.
+func (dialect MysqlDialect) inspectForeignKeys(ctx context.Context, db *sql.DB, tables []trance.SchemaTable) error {
+	rows, err := db.QueryContext(ctx, `SELECT k.TABLE_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME
+FROM information_schema.KEY_COLUMN_USAGE k
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL AND (
+	SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE k2 WHERE k2.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND k2.TABLE_NAME = k.TABLE_NAME AND k2.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+) = 1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, foreignColumn, foreignTable, table string
+		if err := rows.Scan(&table, &column, &foreignTable, &foreignColumn); err != nil {
+			return err
+		}
+		for i := range tables {
+			if tables[i].Name != table {
+				continue
+			}
+			for j := range tables[i].Columns {
+				if tables[i].Columns[j].Name == column {
+					tables[i].Columns[j].ForeignColumn = foreignColumn
+					tables[i].Columns[j].ForeignTable = foreignTable
+				}
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// inspectedType converts information_schema.COLUMNS.COLUMN_TYPE to the types used by ColumnType. Enum values keep their
+// case.
+func inspectedType(columnType string) string {
+	name, args, _ := strings.Cut(columnType, "(")
+	if args != "" {
+		args = "(" + args
+	}
+	columnType = strings.ToUpper(name) + args
+	if columnType == "TINYINT(1)" {
+		return "BOOLEAN"
+	}
+	return mysqlIntegerWidth.ReplaceAllString(columnType, "$1")
+}
+
+// SchemaColumn returns a model field as InspectSchema would return it once created.
+func (dialect MysqlDialect) SchemaColumn(column string, field reflect.StructField) (trance.SchemaColumn, error) {
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return trance.SchemaColumn{}, err
+	}
+	columnType, columnNull := splitColumnType(columnType)
+	schemaColumn := trance.SchemaColumn{
+		Default:  field.Tag.Get("@default"),
+		Name:     column,
+		Nullable: columnNull != " NOT NULL",
+		Primary:  strings.HasSuffix(columnType, " PRIMARY KEY"),
+		Type:     strings.TrimSuffix(columnType, " PRIMARY KEY"),
+		Unique:   field.Tag.Get("@unique") == "true",
+	}
+	schemaColumn.ForeignTable, schemaColumn.ForeignColumn, _ = trance.ForeignKeyTarget(field)
+	return schemaColumn, nil
+}
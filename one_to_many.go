@@ -2,6 +2,8 @@ package trance
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
 )
 
 type OneToMany[To any] struct {
@@ -14,6 +16,31 @@ func (field *OneToMany[To]) All() ([]*To, error) {
 	return field.Query().Filter(field.RelatedColumn, "=", field.RowPk).Collect()
 }
 
+func (field *OneToMany[To]) assignRelated(index any) {
+	field.Rows = index.(map[any][]*To)[field.RowPk]
+}
+
+func (field *OneToMany[To]) fetchRelated(config QueryConfig, dialect Dialect, column string, keys []any) (any, error) {
+	weave := field.Weave()
+	relatedField, ok := weave.Fields[column]
+	if !ok {
+		return nil, fmt.Errorf("trance: invalid db tag of '%s' for fetching related. No fields with a matching column exist on the related model", column)
+	}
+	rows, err := relatedQuery[To](config, dialect).Filter(column, "IN", keys).Collect()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[any][]*To, len(keys))
+	for _, row := range rows {
+		if fk, ok := fieldByIndex(reflect.ValueOf(row).Elem(), relatedField.Index, false).Addr().Interface().(relationField); ok {
+			if key, ok := fk.relatedKey(); ok {
+				index[key] = append(index[key], row)
+			}
+		}
+	}
+	return index, nil
+}
+
 func (field OneToMany[To]) JsonValue() any {
 	weave := field.Weave()
 	results := make([]map[string]any, len(field.Rows))
@@ -32,6 +59,20 @@ func (field OneToMany[To]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(results)
 }
 
+func (field *OneToMany[To]) relatedKey() (any, bool) {
+	return field.RowPk, field.RowPk != nil
+}
+
+func (field *OneToMany[To]) relationKind() RelationKind {
+	return RelationOneToMany
+}
+
+func (field *OneToMany[To]) setRelatedKey(column string, key any) error {
+	field.RelatedColumn = column
+	field.RowPk = key
+	return nil
+}
+
 func (field *OneToMany[To]) Weave() *Weave[To] {
 	return Use[To]()
 }
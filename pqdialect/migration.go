@@ -0,0 +1,38 @@
+package pqdialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/evantbyrne/trance"
+)
+
+// migrationLockKey identifies the advisory lock held while migrations run.
+const migrationLockKey int64 = 7_316_283_142_510_954_431
+
+// LockMigrations takes a session level advisory lock on a dedicated connection of the pool, which is held until the
+// returned function is called.
+func (dialect PqDialect) LockMigrations(ctx context.Context, db *sql.DB) (func() error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return nil, errors.Join(err, conn.Close())
+	}
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		return errors.Join(err, conn.Close())
+	}, nil
+}
+
+// TransactionalDdl is true, because Postgres can roll back schema changes.
+func (dialect PqDialect) TransactionalDdl() bool {
+	return true
+}
+
+// SplitStatements splits SQL files into statements, keeping dollar quoted function bodies whole.
+func (dialect PqDialect) SplitStatements(source string) []string {
+	return trance.SplitStatements(source, trance.SplitStatementsConfig{DollarQuotes: true})
+}
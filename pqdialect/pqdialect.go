@@ -54,6 +54,46 @@ func (dialect PqDialect) BuildDelete(config trance.QueryConfig) (string, []any,
 	return queryString.String(), args, nil
 }
 
+func (dialect PqDialect) BuildIndexCreate(config trance.QueryConfig, index trance.Index, indexCreateConfig trance.IndexCreateConfig) (string, error) {
+	var sql strings.Builder
+	sql.WriteString("CREATE ")
+	if index.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX ")
+	if indexCreateConfig.IfNotExists {
+		sql.WriteString("IF NOT EXISTS ")
+	}
+	sql.WriteString(dialect.QuoteIdentifier(index.Name))
+	sql.WriteString(" ON ")
+
+	// TABLE
+	from, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+	sql.WriteString(from)
+
+	sql.WriteString(" (")
+	sql.WriteString(dialect.buildIndexColumns(index.Columns))
+	sql.WriteString(")")
+	if index.Where != "" {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(index.Where)
+	}
+	return sql.String(), nil
+}
+
+func (dialect PqDialect) BuildIndexDrop(config trance.QueryConfig, name string, indexDropConfig trance.IndexDropConfig) (string, error) {
+	var sql strings.Builder
+	sql.WriteString("DROP INDEX ")
+	if indexDropConfig.IfExists {
+		sql.WriteString("IF EXISTS ")
+	}
+	sql.WriteString(dialect.QuoteIdentifier(name))
+	return sql.String(), nil
+}
+
 func (dialect PqDialect) BuildInsert(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := make([]any, 0)
 	var queryString strings.Builder
@@ -98,6 +138,54 @@ func (dialect PqDialect) BuildInsert(config trance.QueryConfig, rowMap map[strin
 	return queryString.String(), args, nil
 }
 
+// buildConstraints returns table constraints to append to the column definitions of CREATE TABLE.
+func (dialect PqDialect) buildConstraints(config trance.QueryConfig) string {
+	var sql strings.Builder
+	for _, constraint := range config.Constraints {
+		sql.WriteString(",\n\tCONSTRAINT ")
+		sql.WriteString(dialect.QuoteIdentifier(constraint.Name))
+		if constraint.Check != "" {
+			sql.WriteString(" CHECK (")
+			sql.WriteString(constraint.Check)
+			sql.WriteString(")")
+		} else {
+			sql.WriteString(" UNIQUE (")
+			sql.WriteString(dialect.buildIndexColumns(constraint.Columns))
+			sql.WriteString(")")
+		}
+	}
+	return sql.String()
+}
+
+func (dialect PqDialect) buildForeignKeyReference(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	referencedTable, referencedColumn, ok := trance.ForeignKeyTarget(field)
+	if !ok {
+		return "", fmt.Errorf("trance: column '%s' on model for table '%s' is not a foreign key", column, config.Table)
+	}
+	reference := fmt.Sprintf("REFERENCES %s (%s)", dialect.QuoteIdentifier(referencedTable), dialect.QuoteIdentifier(referencedColumn))
+	if tagOnUpdate := field.Tag.Get("@on_update"); tagOnUpdate != "" {
+		// ON UPDATE.
+		reference = fmt.Sprint(reference, " ON UPDATE ", tagOnUpdate)
+	}
+	if tagOnDelete := field.Tag.Get("@on_delete"); tagOnDelete != "" {
+		// ON DELETE.
+		reference = fmt.Sprint(reference, " ON DELETE ", tagOnDelete)
+	}
+	return reference, nil
+}
+
+func (dialect PqDialect) buildIndexColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ",")
+}
+
 func (dialect PqDialect) buildJoins(config trance.QueryConfig, args []any) (string, []any, error) {
 	var queryPart strings.Builder
 	if len(config.Joins) > 0 {
@@ -207,6 +295,30 @@ func (dialect PqDialect) BuildSelect(config trance.QueryConfig) (string, []any,
 	return queryString.String(), args, nil
 }
 
+// BuildSequenceReset advances the sequence of a serial primary key past the largest primary key of the table. Empty
+// tables leave the sequence unchanged.
+func (dialect PqDialect) BuildSequenceReset(config trance.QueryConfig) (string, error) {
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+	for column, field := range config.Fields {
+		if field.Tag.Get("@primary") != "true" {
+			continue
+		}
+		columnType, err := dialect.ColumnType(field)
+		if err != nil {
+			return "", err
+		}
+		if !strings.Contains(columnType, "SERIAL") {
+			return "", nil
+		}
+		return fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), MAX(%s)) FROM %s",
+			quoteString(table), quoteString(column), dialect.QuoteIdentifier(column), table), nil
+	}
+	return "", nil
+}
+
 func (dialect PqDialect) buildTable(config trance.QueryConfig) (string, error) {
 	switch tv := config.Table.(type) {
 	case string:
@@ -240,7 +352,46 @@ func (dialect PqDialect) BuildTableColumnAdd(config trance.QueryConfig, column s
 		return "", err
 	}
 
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", from, dialect.QuoteIdentifier(column), columnType), nil
+	return fmt.Sprintf("%sALTER TABLE %s ADD COLUMN %s %s", dialect.buildEnumType(field), from, dialect.QuoteIdentifier(column), columnType), nil
+}
+
+func (dialect PqDialect) BuildTableColumnAlter(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	if field.Tag.Get("@primary") == "true" {
+		return "", fmt.Errorf("trance: altering primary key column '%s' on table '%s' is not supported", column, config.Table)
+	}
+
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return "", err
+	}
+	columnType, columnNull := splitColumnType(columnType)
+
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	quotedColumn := dialect.QuoteIdentifier(column)
+	var sql strings.Builder
+	sql.WriteString(dialect.buildEnumType(field))
+	fmt.Fprintf(&sql, "ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", table, quotedColumn, columnType, quotedColumn, columnType)
+	switch columnNull {
+	case " NOT NULL":
+		fmt.Fprintf(&sql, ", ALTER COLUMN %s SET NOT NULL", quotedColumn)
+	case " NULL":
+		fmt.Fprintf(&sql, ", ALTER COLUMN %s DROP NOT NULL", quotedColumn)
+	}
+	if tagDefault := field.Tag.Get("@default"); tagDefault != "" {
+		fmt.Fprintf(&sql, ", ALTER COLUMN %s SET DEFAULT %s", quotedColumn, tagDefault)
+	} else {
+		fmt.Fprintf(&sql, ", ALTER COLUMN %s DROP DEFAULT", quotedColumn)
+	}
+	return sql.String(), nil
 }
 
 func (dialect PqDialect) BuildTableColumnDrop(config trance.QueryConfig, column string) (string, error) {
@@ -253,8 +404,29 @@ func (dialect PqDialect) BuildTableColumnDrop(config trance.QueryConfig, column
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", from, dialect.QuoteIdentifier(column)), nil
 }
 
+func (dialect PqDialect) BuildTableColumnRename(config trance.QueryConfig, from string, to string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, dialect.QuoteIdentifier(from), dialect.QuoteIdentifier(to)), nil
+}
+
 func (dialect PqDialect) BuildTableCreate(config trance.QueryConfig, tableCreateConfig trance.TableCreateConfig) (string, error) {
 	var sql strings.Builder
+	fieldNames := maps.Keys(config.Fields)
+	sort.Strings(fieldNames)
+	enumTypes := make(map[string]struct{})
+	for _, fieldName := range fieldNames {
+		enumType := dialect.buildEnumType(config.Fields[fieldName])
+		if _, exists := enumTypes[enumType]; !exists {
+			enumTypes[enumType] = struct{}{}
+			sql.WriteString(enumType)
+		}
+	}
+
 	sql.WriteString("CREATE TABLE ")
 	if tableCreateConfig.IfNotExists {
 		sql.WriteString("IF NOT EXISTS ")
@@ -268,8 +440,6 @@ func (dialect PqDialect) BuildTableCreate(config trance.QueryConfig, tableCreate
 	sql.WriteString(from)
 
 	sql.WriteString(" (")
-	fieldNames := maps.Keys(config.Fields)
-	sort.Strings(fieldNames)
 	for i, fieldName := range fieldNames {
 		field := config.Fields[fieldName]
 		columnType, err := dialect.ColumnType(field)
@@ -284,10 +454,39 @@ func (dialect PqDialect) BuildTableCreate(config trance.QueryConfig, tableCreate
 		sql.WriteString(" ")
 		sql.WriteString(columnType)
 	}
+	sql.WriteString(dialect.buildConstraints(config))
 	sql.WriteString("\n)")
+
+	// Indexes are created after the table.
+	for _, index := range config.Indexes {
+		indexSql, err := dialect.BuildIndexCreate(config, index, trance.IndexCreateConfig{IfNotExists: tableCreateConfig.IfNotExists})
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(";\n")
+		sql.WriteString(indexSql)
+	}
 	return sql.String(), nil
 }
 
+// buildEnumType creates the Postgres type used by an enum field. Types may be shared between tables, so an existing
+// type is skipped.
+func (dialect PqDialect) buildEnumType(field reflect.StructField) string {
+	enumValues, ok := trance.EnumValues(field.Type)
+	if !ok || len(enumValues) == 0 || field.Tag.Get("@type") != "" {
+		return ""
+	}
+	quotedValues := make([]string, len(enumValues))
+	for i, enumValue := range enumValues {
+		quotedValues[i] = quoteString(enumValue)
+	}
+	return fmt.Sprintf(
+		"DO $$ BEGIN CREATE TYPE %s AS ENUM (%s); EXCEPTION WHEN duplicate_object THEN NULL; END $$;\n",
+		dialect.QuoteIdentifier(trance.EnumTypeName(field)),
+		strings.Join(quotedValues, ","))
+}
+
+// BuildTableDrop drops a table along with the enum types of its fields. Types still used by other tables are kept.
 func (dialect PqDialect) BuildTableDrop(config trance.QueryConfig, tableDropConfig trance.TableDropConfig) (string, error) {
 	var queryString strings.Builder
 	queryString.WriteString("DROP TABLE ")
@@ -302,9 +501,63 @@ func (dialect PqDialect) BuildTableDrop(config trance.QueryConfig, tableDropConf
 	}
 	queryString.WriteString(from)
 
+	fieldNames := maps.Keys(config.Fields)
+	sort.Strings(fieldNames)
+	enumTypes := make(map[string]struct{})
+	for _, fieldName := range fieldNames {
+		field := config.Fields[fieldName]
+		if dialect.buildEnumType(field) == "" {
+			continue
+		}
+		enumType := trance.EnumTypeName(field)
+		if _, exists := enumTypes[enumType]; !exists {
+			enumTypes[enumType] = struct{}{}
+			fmt.Fprintf(&queryString,
+				";\nDO $$ BEGIN DROP TYPE IF EXISTS %s; EXCEPTION WHEN dependent_objects_still_exist THEN NULL; END $$",
+				dialect.QuoteIdentifier(enumType))
+		}
+	}
+
 	return queryString.String(), nil
 }
 
+func (dialect PqDialect) BuildTableForeignKeyAdd(config trance.QueryConfig, column string) (string, error) {
+	reference, err := dialect.buildForeignKeyReference(config, column)
+	if err != nil {
+		return "", err
+	}
+
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	name := trance.ForeignKeyName(fmt.Sprint(config.Table), column)
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) %s", table, dialect.QuoteIdentifier(name), dialect.QuoteIdentifier(column), reference), nil
+}
+
+func (dialect PqDialect) BuildTableForeignKeyDrop(config trance.QueryConfig, column string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	name := trance.ForeignKeyName(fmt.Sprint(config.Table), column)
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, dialect.QuoteIdentifier(name)), nil
+}
+
+func (dialect PqDialect) BuildTableRename(config trance.QueryConfig, name string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, dialect.QuoteIdentifier(name)), nil
+}
+
 func (dialect PqDialect) BuildUpdate(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := append([]any(nil), config.Params...)
 	var queryString strings.Builder
@@ -371,6 +624,17 @@ func (dialect PqDialect) ColumnType(field reflect.StructField) (string, error) {
 		return tagType, nil
 	}
 
+	if nullableType, ok := trance.NullableType(field.Type); ok && field.Tag.Get("@primary") != "true" {
+		// Pointers and sql.Null[T].
+		nullableField := field
+		nullableField.Type = nullableType
+		columnType, err := dialect.ColumnType(nullableField)
+		if err != nil {
+			return "", err
+		}
+		return strings.Replace(columnType, " NOT NULL", " NULL", 1), nil
+	}
+
 	fieldInstance := reflect.Indirect(reflect.New(field.Type)).Interface()
 	var columnNull string
 	var columnPrimary string
@@ -394,6 +658,40 @@ func (dialect PqDialect) ColumnType(field reflect.StructField) (string, error) {
 		}
 	}
 
+	if sqlType, ok := trance.RegisteredSqlType(dialect, field.Type); columnType == "" && ok {
+		// Types added with trance.RegisterType.
+		columnNull = " NOT NULL"
+		columnType = sqlType
+	}
+
+	if enumValues, ok := trance.EnumValues(field.Type); columnType == "" && ok && len(enumValues) > 0 {
+		// Enum types are created by BuildTableCreate and BuildTableColumnAdd.
+		columnNull = " NOT NULL"
+		columnType = dialect.QuoteIdentifier(trance.EnumTypeName(field))
+	}
+
+	if columnType == "" && trance.IsJsonField(field) {
+		columnNull = " NOT NULL"
+		columnType = "JSONB"
+	}
+
+	if columnType == "" && field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+		// Arrays.
+		elementField := field
+		elementField.Type = field.Type.Elem()
+		elementField.Tag = reflect.StructTag(fmt.Sprintf(`@length:"%s" @precision:"%s" @scale:"%s" @time_zone:"%s"`, field.Tag.Get("@length"), field.Tag.Get("@precision"), field.Tag.Get("@scale"), field.Tag.Get("@time_zone")))
+		elementType, err := dialect.ColumnType(elementField)
+		if err != nil {
+			return "", err
+		}
+		elementType = strings.TrimSuffix(strings.TrimSuffix(elementType, " NOT NULL"), " NULL")
+		if strings.Contains(elementType, " REFERENCES ") {
+			return "", fmt.Errorf("trance: Unsupported array element type: %s", field.Type.Elem())
+		}
+		columnNull = " NOT NULL"
+		columnType = elementType + "[]"
+	}
+
 	if columnType == "" {
 		switch fieldInstance.(type) {
 		case bool:
@@ -452,6 +750,17 @@ func (dialect PqDialect) ColumnType(field reflect.StructField) (string, error) {
 				columnType = "TEXT"
 			}
 
+		case trance.Decimal:
+			columnNull = " NOT NULL"
+			columnType = "NUMERIC"
+			if tagPrecision := field.Tag.Get("@precision"); tagPrecision != "" {
+				if tagScale := field.Tag.Get("@scale"); tagScale != "" {
+					columnType = fmt.Sprintf("NUMERIC(%s,%s)", tagPrecision, tagScale)
+				} else {
+					columnType = fmt.Sprintf("NUMERIC(%s)", tagPrecision)
+				}
+			}
+
 		case time.Time:
 			columnNull = " NOT NULL"
 			if tagTimeZone := field.Tag.Get("@time_zone"); tagTimeZone == "true" {
@@ -564,3 +873,19 @@ func QuoteIdentifier(name string) string {
 	}
 	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
 }
+
+// quoteString quotes a string literal for use in DDL statements, which do not accept parameters.
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// splitColumnType splits a column definition returned by ColumnType into its type and NULL constraint.
+func splitColumnType(columnType string) (string, string) {
+	if i := strings.Index(columnType, " NOT NULL"); i > -1 {
+		return columnType[:i], " NOT NULL"
+	}
+	if i := strings.Index(columnType, " NULL"); i > -1 {
+		return columnType[:i], " NULL"
+	}
+	return columnType, ""
+}
@@ -1,11 +1,14 @@
 package pqdialect
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/evantbyrne/trance"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -87,6 +90,50 @@ func TestBuildDelete(t *testing.T) {
 	}
 }
 
+func TestBuildIndexCreate(t *testing.T) {
+	dialect := PqDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := `CREATE INDEX "testmodel_a_b_idx" ON "testmodel" ("a","b")`
+	queryString, err := dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a", "b"}, Name: "testmodel_a_b_idx"}, trance.IndexCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = `CREATE UNIQUE INDEX IF NOT EXISTS "testmodel_a_key" ON "testmodel" ("a") WHERE b IS NULL`
+	queryString, err = dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a"}, Name: "testmodel_a_key", Unique: true, Where: "b IS NULL"}, trance.IndexCreateConfig{IfNotExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildIndexDrop(t *testing.T) {
+	dialect := PqDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := `DROP INDEX "testmodel_a_b_idx"`
+	queryString, err := dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = `DROP INDEX IF EXISTS "testmodel_a_b_idx"`
+	queryString, err = dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{IfExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildInsert(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -195,6 +242,63 @@ func TestBuildSelect(t *testing.T) {
 		t.Errorf("Expected '%s', got '%s'", expectedArgs, args)
 	}
 
+	// JSON containment
+	config = trance.QueryWith[testModel](trance.WeaveConfig{NoCache: true}).Filter("value1", "@>", trance.JSON[any]{Data: map[string]any{"foo": "bar"}}).Config
+	config.Fields = weave.Fields
+	config.Table = "testmodel"
+	expectedSql = `SELECT * FROM "testmodel" WHERE "value1" @> $1`
+	queryString, args, err = dialect.BuildSelect(config)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+	if len(args) != 1 {
+		t.Fatalf("Expected 1 arg, got '%#v'", args)
+	}
+	if document, err := args[0].(trance.JSON[any]).Value(); err != nil || document != `{"foo":"bar"}` {
+		t.Errorf(`Expected '{"foo":"bar"}', got '%#v'`, args[0])
+	}
+
+	// Array containment
+	config = trance.QueryWith[testModel](trance.WeaveConfig{NoCache: true}).Filter("value1", "@>", []string{"a"}).Config
+	config.Fields = weave.Fields
+	config.Table = "testmodel"
+	expectedSql = `SELECT * FROM "testmodel" WHERE "value1" @> $1`
+	queryString, args, err = dialect.BuildSelect(config)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+	if len(args) != 1 {
+		t.Fatalf("Expected 1 arg, got '%#v'", args)
+	}
+	if array, err := args[0].(trance.SqlArray).Value(); err != nil || array != `{"a"}` {
+		t.Errorf(`Expected '{"a"}', got '%#v'`, args[0])
+	}
+
+	// ANY
+	config = trance.QueryWith[testModel](trance.WeaveConfig{NoCache: true}).Filter("id", "= ANY", []int64{1, 2}).Config
+	config.Fields = weave.Fields
+	config.Table = "testmodel"
+	expectedSql = `SELECT * FROM "testmodel" WHERE "id" = ANY ($1)`
+	queryString, args, err = dialect.BuildSelect(config)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+	if len(args) != 1 {
+		t.Fatalf("Expected 1 arg, got '%#v'", args)
+	}
+	if array, err := args[0].(trance.SqlArray).Value(); err != nil || array != "{1,2}" {
+		t.Errorf("Expected '{1,2}', got '%#v'", args[0])
+	}
+
 	// JOIN
 	config = trance.QueryWith[testModel](trance.WeaveConfig{NoCache: true}).Select(trance.Unsafe("*")).Join("groups", trance.Or(
 		trance.Q("groups.id", "=", trance.Column("accounts.group_id")),
@@ -271,6 +375,43 @@ func TestBuildTableColumnAdd(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnAlter(t *testing.T) {
+	type testModel struct {
+		Id    int64   `@:"id" @primary:"true"`
+		Count int64   `@:"test_count"`
+		Value *string `@:"test_value" @default:"''" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := `ALTER TABLE "testmodel" ALTER COLUMN "test_value" TYPE VARCHAR(100) USING "test_value"::VARCHAR(100), ALTER COLUMN "test_value" DROP NOT NULL, ALTER COLUMN "test_value" SET DEFAULT ''`
+	queryString, err := dialect.BuildTableColumnAlter(config, "test_value")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = `ALTER TABLE "testmodel" ALTER COLUMN "test_count" TYPE BIGINT USING "test_count"::BIGINT, ALTER COLUMN "test_count" SET NOT NULL, ALTER COLUMN "test_count" DROP DEFAULT`
+	queryString, err = dialect.BuildTableColumnAlter(config, "test_count")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableColumnAlter(config, "id"); err == nil {
+		t.Error("Expected error for primary key column")
+	}
+}
+
 func TestBuildTableColumnDrop(t *testing.T) {
 	dialect := PqDialect{}
 	config := trance.QueryConfig{Table: "testmodel"}
@@ -284,6 +425,19 @@ func TestBuildTableColumnDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnRename(t *testing.T) {
+	dialect := PqDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := `ALTER TABLE "testmodel" RENAME COLUMN "a" TO "b"`
+	queryString, err := dialect.BuildTableColumnRename(config, "a", "b")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildTableCreate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -320,6 +474,157 @@ func TestBuildTableCreate(t *testing.T) {
 	if queryString != expectedSql {
 		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
 	}
+
+	// Embedded and prefixed structs.
+	type testTimestamps struct {
+		CreatedAt time.Time `@:"created_at"`
+	}
+	type testAddress struct {
+		City string `@:"city" @length:"100"`
+	}
+	type testNestedModel struct {
+		testTimestamps
+		Id      int64       `@:"id" @primary:"true"`
+		Address testAddress `@prefix:"address_"`
+	}
+	nestedWeave := trance.UseWith[testNestedModel](trance.WeaveConfig{NoCache: true})
+	config = trance.QueryConfig{
+		Fields: nestedWeave.Fields,
+		Table:  "testnestedmodel",
+	}
+	expectedSql = `CREATE TABLE "testnestedmodel" (
+	"address_city" VARCHAR(100) NOT NULL,
+	"created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+	"id" BIGSERIAL PRIMARY KEY NOT NULL
+)`
+	queryString, err = dialect.BuildTableCreate(config, trance.TableCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+type testStatus string
+
+func (status testStatus) Values() []string {
+	return []string{"active", "it's archived"}
+}
+
+func TestBuildTableCreateEnum(t *testing.T) {
+	type testModel struct {
+		Id       int64       `@:"id" @primary:"true"`
+		Previous *testStatus `@:"previous"`
+		Status   testStatus  `@:"status"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	enumSql := `DO $$ BEGIN CREATE TYPE "teststatus" AS ENUM ('active','it''s archived'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;` + "\n"
+	expectedSql := enumSql + `CREATE TABLE "testmodel" (` + "\n" +
+		`	"id" BIGSERIAL PRIMARY KEY NOT NULL,` + "\n" +
+		`	"previous" "teststatus" NULL,` + "\n" +
+		`	"status" "teststatus" NOT NULL` + "\n" +
+		`)`
+	queryString, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = enumSql + `ALTER TABLE "testmodel" ADD COLUMN "status" "teststatus" NOT NULL`
+	queryString, err = dialect.BuildTableColumnAdd(config, "status")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = `DROP TABLE "testmodel";` + "\n" +
+		`DO $$ BEGIN DROP TYPE IF EXISTS "teststatus"; EXCEPTION WHEN dependent_objects_still_exist THEN NULL; END $$`
+	queryString, err = dialect.BuildTableDrop(config, trance.TableDropConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildSequenceReset(t *testing.T) {
+	type testModel struct {
+		Id   int64  `@:"id" @primary:"true"`
+		Name string `@:"name"`
+	}
+	type testSlugModel struct {
+		Slug string `@:"slug" @primary:"true" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	config := trance.QueryConfig{Fields: trance.UseWith[testModel](trance.WeaveConfig{NoCache: true}).Fields, Table: "testmodel"}
+	expectedSql := `SELECT setval(pg_get_serial_sequence('"testmodel"', 'id'), MAX("id")) FROM "testmodel"`
+	queryString, err := dialect.BuildSequenceReset(config)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	config = trance.QueryConfig{Fields: trance.UseWith[testSlugModel](trance.WeaveConfig{NoCache: true}).Fields, Table: "testslugmodel"}
+	queryString, err = dialect.BuildSequenceReset(config)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != "" {
+		t.Errorf("Expected no statement for a primary key without a sequence, got '%s'", queryString)
+	}
+}
+
+func TestBuildTableCreateIndexes(t *testing.T) {
+	type testModel struct {
+		Id      int64  `@:"id" @primary:"true"`
+		GroupId int64  `@:"group_id" @index:"true"`
+		Slug    string `@:"slug" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Constraints: []trance.Constraint{
+			{Check: "id > 0", Name: "testmodel_check1"},
+			{Columns: []string{"group_id", "slug"}, Name: "testmodel_group_id_slug_key"},
+		},
+		Fields:  weave.Fields,
+		Indexes: weave.Indexes,
+		Table:   "testmodel",
+	}
+	expectedSql := `CREATE TABLE IF NOT EXISTS "testmodel" (` + "\n" +
+		`	"group_id" BIGINT NOT NULL,` + "\n" +
+		`	"id" BIGSERIAL PRIMARY KEY NOT NULL,` + "\n" +
+		`	"slug" VARCHAR(100) NOT NULL,` + "\n" +
+		`	CONSTRAINT "testmodel_check1" CHECK (id > 0),` + "\n" +
+		`	CONSTRAINT "testmodel_group_id_slug_key" UNIQUE ("group_id","slug")` + "\n" +
+		`);` + "\n" +
+		`CREATE INDEX IF NOT EXISTS "testmodel_group_id_idx" ON "testmodel" ("group_id")`
+	queryString, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{IfNotExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
 }
 
 func TestBuildTableDrop(t *testing.T) {
@@ -344,6 +649,59 @@ func TestBuildTableDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableForeignKey(t *testing.T) {
+	type testFkInt struct {
+		Id int64 `@:"id" @primary:"true"`
+	}
+
+	type testModel struct {
+		ForeignKey trance.ForeignKey[testFkInt] `@:"test_fk_id" @on_delete:"CASCADE"`
+		Value      int64                        `@:"test_value"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := `ALTER TABLE "testmodel" ADD CONSTRAINT "testmodel_test_fk_id_fkey" FOREIGN KEY ("test_fk_id") REFERENCES "testfkint" ("id") ON DELETE CASCADE`
+	queryString, err := dialect.BuildTableForeignKeyAdd(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableForeignKeyAdd(config, "test_value"); err == nil {
+		t.Error("Expected error for column that isn't a foreign key")
+	}
+
+	expectedSql = `ALTER TABLE "testmodel" DROP CONSTRAINT "testmodel_test_fk_id_fkey"`
+	queryString, err = dialect.BuildTableForeignKeyDrop(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildTableRename(t *testing.T) {
+	dialect := PqDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := `ALTER TABLE "testmodel" RENAME TO "renamed"`
+	queryString, err := dialect.BuildTableRename(config, "renamed")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildUpdate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -376,6 +734,45 @@ func TestBuildUpdate(t *testing.T) {
 	}
 }
 
+func TestInspectSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT c.table_name").WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "udt_name", "character_maximum_length", "numeric_precision", "numeric_scale", "is_nullable", "column_default", "primary"}).
+		AddRow("accounts", "id", "bigint", "int8", nil, 64, 0, "NO", "nextval('accounts_id_seq'::regclass)", true).
+		AddRow("accounts", "name", "character varying", "varchar", 100, nil, nil, "YES", "'x'::character varying", false).
+		AddRow("accounts", "status", "USER-DEFINED", "status", nil, nil, nil, "NO", nil, false).
+		AddRow("accounts", "tags", "ARRAY", "_text", nil, nil, nil, "NO", nil, false).
+		AddRow("groups", "total", "numeric", "numeric", nil, 10, 2, "NO", nil, false))
+	mock.ExpectQuery("SELECT kcu.table_name").WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "constraint_type", "foreign_table", "foreign_column"}).
+		AddRow("accounts", "name", "UNIQUE", "", "").
+		AddRow("accounts", "status", "FOREIGN KEY", "statuses", "id"))
+	tables, err := PqDialect{}.InspectSchema(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []trance.SchemaTable{
+		{Name: "accounts", Columns: []trance.SchemaColumn{
+			{Name: "id", Primary: true, Type: "BIGSERIAL"},
+			{Default: "'x'::character varying", Name: "name", Nullable: true, Type: "VARCHAR(100)", Unique: true},
+			{ForeignColumn: "id", ForeignTable: "statuses", Name: "status", Type: `"status"`},
+			{Name: "tags", Type: "TEXT[]"},
+		}},
+		{Name: "groups", Columns: []trance.SchemaColumn{
+			{Name: "total", Type: "NUMERIC(10,2)"},
+		}},
+	}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, tables)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestColumnType(t *testing.T) {
 	type testFkInt struct {
 		Id int64 `@:"id" @primary:"true"`
@@ -410,6 +807,18 @@ func TestColumnType(t *testing.T) {
 		ForiegnKey     trance.ForeignKey[testFkString]  `@:"test_fk_id" @on_delete:"CASCADE" @on_update:"CASCADE"`
 		ForiegnKeyNull trance.NullForeignKey[testFkInt] `@:"test_fk_null_id" @on_delete:"SET NULL"`
 		Unique         string                           `@:"test_unique" @length:"255" @unique:"true"`
+		Json           trance.JSON[map[string]any]      `@:"test_json"`
+		JsonNull       *map[string]any                  `@:"test_json_null" @json:"true"`
+		JsonTag        []string                         `@:"test_json_tag" @json:"true" @default:"'[]'"`
+		GenericNull    sql.Null[int64]                  `@:"test_generic_null"`
+		PointerText    *string                          `@:"test_pointer_text" @default:"'foo'"`
+		PointerTime    *time.Time                       `@:"test_pointer_time"`
+		Decimal        trance.Decimal                   `@:"test_decimal"`
+		DecimalMoney   trance.Decimal                   `@:"test_decimal_money" @precision:"12" @scale:"2"`
+		DecimalNull    *trance.Decimal                  `@:"test_decimal_null" @precision:"8"`
+		ArrayInt       []int64                          `@:"test_array_int"`
+		ArrayText      []string                         `@:"test_array_text"`
+		ArrayVarchar   []string                         `@:"test_array_varchar" @length:"50" @default:"'{}'"`
 	}
 	defer trance.PurgeWeaves()
 
@@ -438,6 +847,18 @@ func TestColumnType(t *testing.T) {
 		"test_fk_id":          `VARCHAR(100) NOT NULL REFERENCES "testfkstring" ("id") ON UPDATE CASCADE ON DELETE CASCADE`,
 		"test_fk_null_id":     `BIGINT NULL REFERENCES "testfkint" ("id") ON DELETE SET NULL`,
 		"test_unique":         `VARCHAR(255) NOT NULL UNIQUE`,
+		"test_json":           "JSONB NOT NULL",
+		"test_json_null":      "JSONB NULL",
+		"test_json_tag":       "JSONB NOT NULL DEFAULT '[]'",
+		"test_generic_null":   "BIGINT NULL",
+		"test_pointer_text":   "TEXT NULL DEFAULT 'foo'",
+		"test_pointer_time":   "TIMESTAMP WITHOUT TIME ZONE NULL",
+		"test_decimal":        "NUMERIC NOT NULL",
+		"test_decimal_money":  "NUMERIC(12,2) NOT NULL",
+		"test_decimal_null":   "NUMERIC(8) NULL",
+		"test_array_int":      "BIGINT[] NOT NULL",
+		"test_array_text":     "TEXT[] NOT NULL",
+		"test_array_varchar":  "VARCHAR(50)[] NOT NULL DEFAULT '{}'",
 	}
 
 	dialect := PqDialect{}
@@ -456,3 +877,89 @@ func TestColumnType(t *testing.T) {
 		}
 	}
 }
+
+func TestColumnTypeRegistered(t *testing.T) {
+	type testNetwork struct {
+		Mask []byte
+	}
+	type testModel struct {
+		Network     testNetwork  `@:"network"`
+		NetworkNull *testNetwork `@:"network_null" @unique:"true"`
+	}
+	trance.RegisterType(trance.ColumnType[testNetwork]{
+		SqlTypes: map[trance.Dialect]string{PqDialect{}: "CIDR"},
+	})
+
+	expected := map[string]string{
+		"Network":     "CIDR NOT NULL",
+		"NetworkNull": "CIDR NULL UNIQUE",
+	}
+	for name, expectedType := range expected {
+		field, _ := reflect.TypeOf(testModel{}).FieldByName(name)
+		columnType, err := (PqDialect{}).ColumnType(field)
+		if err != nil {
+			t.Fatalf(`dialect.ColumnType() threw error for '%#v': %s`, field, err)
+		}
+		if columnType != expectedType {
+			t.Errorf("Expected '%s', got '%s'", expectedType, columnType)
+		}
+	}
+}
+
+func TestSchemaColumn(t *testing.T) {
+	type testModel struct {
+		Id    int64          `@:"id" @primary:"true"`
+		Name  *string        `@:"name" @default:"'x'" @length:"100"`
+		Total trance.Decimal `@:"total" @precision:"10"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := PqDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	expected := []trance.SchemaColumn{
+		{Name: "id", Primary: true, Type: "BIGSERIAL"},
+		{Default: "'x'", Name: "name", Nullable: true, Type: "VARCHAR(100)"},
+		{Name: "total", Type: "NUMERIC(10,0)"},
+	}
+	for _, expectedColumn := range expected {
+		column, err := dialect.SchemaColumn(expectedColumn.Name, weave.Fields[expectedColumn.Name])
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if column != expectedColumn {
+			t.Errorf("Expected '%#v', got '%#v'", expectedColumn, column)
+		}
+	}
+}
+
+func TestLockMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	unlock, err := PqDialect{}.LockMigrations(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	source := "CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a := 'x;'; RETURN NEW; END; $body$ LANGUAGE plpgsql;\nSELECT $$;$$, $1;"
+	expected := []string{
+		"CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a := 'x;'; RETURN NEW; END; $body$ LANGUAGE plpgsql",
+		"SELECT $$;$$, $1",
+	}
+	if actual := (PqDialect{}).SplitStatements(source); !slices.Equal(expected, actual) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,164 @@
+package pqdialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+var pqUdtTypes = map[string]string{
+	"bool":        "BOOLEAN",
+	"float8":      "DOUBLE PRECISION",
+	"int2":        "SMALLINT",
+	"int4":        "INTEGER",
+	"int8":        "BIGINT",
+	"jsonb":       "JSONB",
+	"numeric":     "NUMERIC",
+	"text":        "TEXT",
+	"timestamp":   "TIMESTAMP WITHOUT TIME ZONE",
+	"timestamptz": "TIMESTAMP WITH TIME ZONE",
+	"varchar":     "VARCHAR",
+}
+
+var pqSerialTypes = map[string]string{
+	"BIGINT":   "BIGSERIAL",
+	"INTEGER":  "SERIAL",
+	"SMALLINT": "SMALLSERIAL",
+}
+
+// InspectSchema reads tables in the current schema from information_schema.
+func (dialect PqDialect) InspectSchema(ctx context.Context, db *sql.DB) ([]trance.SchemaTable, error) {
+	rows, err := db.QueryContext(ctx, `SELECT c.table_name, c.column_name, c.data_type, c.udt_name, c.character_maximum_length, c.numeric_precision, c.numeric_scale, c.is_nullable, c.column_default, EXISTS (
+	SELECT 1 FROM information_schema.table_constraints tc
+	JOIN information_schema.key_column_usage kcu ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+	WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema AND tc.table_name = c.table_name AND kcu.column_name = c.column_name
+)
+FROM information_schema.columns c
+JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'
+ORDER BY c.table_name, c.ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]trance.SchemaTable, 0)
+	for rows.Next() {
+		var column trance.SchemaColumn
+		var columnDefault sql.NullString
+		var dataType, isNullable, table, udtName string
+		var maxLength, precision, scale sql.NullInt64
+		if err := rows.Scan(&table, &column.Name, &dataType, &udtName, &maxLength, &precision, &scale, &isNullable, &columnDefault, &column.Primary); err != nil {
+			return nil, err
+		}
+		column.Default = columnDefault.String
+		column.Nullable = isNullable == "YES"
+		column.Type = dialect.inspectedType(dataType, udtName, maxLength, precision, scale)
+		if serialType, ok := pqSerialTypes[column.Type]; ok && column.Primary && strings.HasPrefix(column.Default, "nextval(") {
+			column.Default = ""
+			column.Type = serialType
+		}
+		if len(tables) == 0 || tables[len(tables)-1].Name != table {
+			tables = append(tables, trance.SchemaTable{Name: table})
+		}
+		tables[len(tables)-1].Columns = append(tables[len(tables)-1].Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tables, dialect.inspectKeys(ctx, db, tables)
+}
+
+// inspectKeys sets single column unique constraints and foreign keys on inspected columns.
+func (dialect PqDialect) inspectKeys(ctx context.Context, db *sql.DB, tables []trance.SchemaTable) error {
+	rows, err := db.QueryContext(ctx, `SELECT kcu.table_name, kcu.column_name, tc.constraint_type, COALESCE(ccu.table_name, ''), COALESCE(ccu.column_name, '')
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+LEFT JOIN information_schema.constraint_column_usage ccu ON tc.constraint_type = 'FOREIGN KEY' AND ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+WHERE tc.table_schema = current_schema() AND tc.constraint_type IN ('FOREIGN KEY', 'UNIQUE') AND (
+	SELECT COUNT(*) FROM information_schema.key_column_usage k WHERE k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name
+) = 1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, constraintType, foreignColumn, foreignTable, table string
+		if err := rows.Scan(&table, &column, &constraintType, &foreignTable, &foreignColumn); err != nil {
+			return err
+		}
+		for i := range tables {
+			if tables[i].Name != table {
+				continue
+			}
+			for j := range tables[i].Columns {
+				if tables[i].Columns[j].Name != column {
+					continue
+				}
+				if constraintType == "UNIQUE" {
+					tables[i].Columns[j].Unique = true
+				} else {
+					tables[i].Columns[j].ForeignColumn = foreignColumn
+					tables[i].Columns[j].ForeignTable = foreignTable
+				}
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func (dialect PqDialect) inspectedType(dataType string, udtName string, maxLength sql.NullInt64, precision sql.NullInt64, scale sql.NullInt64) string {
+	switch dataType {
+	case "ARRAY":
+		elementType := strings.TrimPrefix(udtName, "_")
+		if mappedType, ok := pqUdtTypes[elementType]; ok {
+			return mappedType + "[]"
+		}
+		return strings.ToUpper(elementType) + "[]"
+
+	case "character varying":
+		if maxLength.Valid {
+			return fmt.Sprintf("VARCHAR(%d)", maxLength.Int64)
+		}
+		return "VARCHAR"
+
+	case "numeric":
+		if precision.Valid && scale.Valid {
+			return fmt.Sprintf("NUMERIC(%d,%d)", precision.Int64, scale.Int64)
+		}
+		return "NUMERIC"
+
+	case "USER-DEFINED":
+		// Enum types.
+		return dialect.QuoteIdentifier(udtName)
+	}
+	return strings.ToUpper(dataType)
+}
+
+// SchemaColumn returns a model field as InspectSchema would return it once created.
+func (dialect PqDialect) SchemaColumn(column string, field reflect.StructField) (trance.SchemaColumn, error) {
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return trance.SchemaColumn{}, err
+	}
+	columnType, columnNull := splitColumnType(columnType)
+	schemaColumn := trance.SchemaColumn{
+		Default:  field.Tag.Get("@default"),
+		Name:     column,
+		Nullable: columnNull != " NOT NULL",
+		Primary:  strings.HasSuffix(columnType, " PRIMARY KEY"),
+		Type:     strings.TrimSuffix(columnType, " PRIMARY KEY"),
+		Unique:   field.Tag.Get("@unique") == "true",
+	}
+	schemaColumn.ForeignTable, schemaColumn.ForeignColumn, _ = trance.ForeignKeyTarget(field)
+	if strings.HasPrefix(schemaColumn.Type, "NUMERIC(") && !strings.Contains(schemaColumn.Type, ",") {
+		// Postgres reports NUMERIC(p) as NUMERIC(p,0).
+		schemaColumn.Type = strings.TrimSuffix(schemaColumn.Type, ")") + ",0)"
+	}
+	return schemaColumn, nil
+}
@@ -3,10 +3,10 @@ package trance
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
-	"strings"
 
 	"golang.org/x/exp/maps"
 )
@@ -22,11 +22,13 @@ type JsonValuer interface {
 }
 
 type QueryConfig struct {
+	Constraints  []Constraint
 	Count        bool
 	Context      context.Context
 	FetchRelated []string
 	Fields       map[string]reflect.StructField
 	Filters      []FilterClause
+	Indexes      []Index
 	Joins        []JoinClause
 	Limit        any
 	Offset       any
@@ -43,15 +45,17 @@ type QueryStream[T any] struct {
 	Weave  *Weave[T]
 	Rows   *sql.Rows
 
-	dialect Dialect
+	dialect  Dialect
+	recorder *migrationRecorder
 }
 
 func (query *QueryStream[T]) Clone() *QueryStream[T] {
 	return &QueryStream[T]{
-		Error:   query.Error,
-		Config:  query.Config,
-		Weave:   query.Weave,
-		dialect: query.dialect,
+		Error:    query.Error,
+		Config:   query.Config,
+		Weave:    query.Weave,
+		dialect:  query.dialect,
+		recorder: query.recorder,
 	}
 }
 
@@ -144,7 +148,10 @@ func (query *QueryStream[T]) AllToMap() *MapListStream {
 }
 
 func (query *QueryStream[T]) configure() {
+	query.Config.Constraints = query.Weave.Constraints
 	query.Config.Fields = query.Weave.Fields
+	query.Config.Filters = jsonContainmentFilters(query.Config.Filters, query.Weave.Fields)
+	query.Config.Indexes = query.Weave.Indexes
 	if query.Config.Table == nil {
 		query.Config.Table = query.Weave.Table
 	}
@@ -201,7 +208,22 @@ func (query *QueryStream[T]) CollectFirst() (*T, error) {
 	return query.First().Collect()
 }
 
+// dbDryRunError returns an error when a dry run is recording migration statements but the query is not recording, such
+// as queries created with Query rather than MigrationQuery, so that dry runs never write to the database.
+func (query *QueryStream[T]) dbDryRunError() error {
+	if query.recorder == nil && migrationRecorder_.Load() != nil {
+		return MigrationDryRunError{}
+	}
+	return nil
+}
+
 func (query *QueryStream[T]) dbExec(db *sql.DB, queryString string, args ...any) (sql.Result, error) {
+	if query.recorder != nil {
+		return query.recorder.exec(queryString, args...)
+	}
+	if err := query.dbDryRunError(); err != nil {
+		return nil, err
+	}
 	if query.Config.Transaction != nil {
 		if query.Config.Context != nil {
 			return query.Config.Transaction.ExecContext(query.Config.Context, queryString, args...)
@@ -215,6 +237,46 @@ func (query *QueryStream[T]) dbExec(db *sql.DB, queryString string, args ...any)
 	return db.Exec(queryString, args...)
 }
 
+// dbExecRebuild executes table alterations, which dialects implementing TableRebuilder run themselves.
+func (query *QueryStream[T]) dbExecRebuild(db *sql.DB, queryString string) (sql.Result, error) {
+	rebuilder, ok := query.dialect.(TableRebuilder)
+	if !ok || query.recorder != nil {
+		return query.dbExecTransaction(db, queryString)
+	}
+	if err := query.dbDryRunError(); err != nil {
+		return nil, err
+	}
+	ctx := query.Config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return rebuilder.ExecTableRebuild(ctx, db, query.Config, queryString)
+}
+
+// dbExecTransaction executes statements inside the configured transaction, or inside a new transaction.
+func (query *QueryStream[T]) dbExecTransaction(db *sql.DB, queryString string, args ...any) (sql.Result, error) {
+	if query.Config.Transaction != nil || query.recorder != nil {
+		return query.dbExec(db, queryString, args...)
+	}
+	if err := query.dbDryRunError(); err != nil {
+		return nil, err
+	}
+
+	ctx := query.Config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errors.Join(err, tx.Rollback())
+	}
+	return result, tx.Commit()
+}
+
 func (query *QueryStream[T]) dbQuery(db *sql.DB, queryString string, args ...any) (*sql.Rows, error) {
 	if query.Config.Transaction != nil {
 		if query.Config.Context != nil {
@@ -465,6 +527,67 @@ func (query *QueryStream[T]) FirstToMap() *MapStream {
 	return result
 }
 
+func (query *QueryStream[T]) IndexCreate(index Index, indexCreateConfig ...IndexCreateConfig) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	var config IndexCreateConfig
+	if len(indexCreateConfig) > 0 {
+		config = indexCreateConfig[0]
+	}
+	if index.Name == "" {
+		index.Name = IndexName(query.Weave.Table, index)
+	}
+	queryString, err := query.dialect.BuildIndexCreate(query.Config, index, config)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExec(db, queryString)
+	return result
+}
+
+func (query *QueryStream[T]) IndexDrop(name string, indexDropConfig ...IndexDropConfig) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	var config IndexDropConfig
+	if len(indexDropConfig) > 0 {
+		config = indexDropConfig[0]
+	}
+	queryString, err := query.dialect.BuildIndexDrop(query.Config, name, config)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExec(db, queryString)
+	return result
+}
+
 func (query *QueryStream[T]) Insert(row *T) *QueryResultStreamer[T] {
 	result := &QueryResultStreamer[T]{
 		Error:       query.Error,
@@ -499,8 +622,8 @@ func (query *QueryStream[T]) Insert(row *T) *QueryResultStreamer[T] {
 	}
 
 	// Set primary key if zero.
-	if query.Weave.PrimaryField != "" {
-		primaryField := reflect.ValueOf(row).Elem().FieldByName(query.Weave.PrimaryField)
+	if query.Weave.PrimaryColumn != "" {
+		primaryField := query.Weave.primaryValue(reflect.ValueOf(row).Elem(), true)
 		if primaryField.IsValid() && primaryField.IsZero() {
 			switch primaryField.Type().String() {
 			case "int", "int8", "int16", "int32", "int64":
@@ -662,7 +785,7 @@ func (query *QueryStream[T]) Page(direction SortDirection, limit uint64, reverse
 				firstRow := rows[0]
 				firstValue := reflect.ValueOf(firstRow).Elem()
 				page.First = firstRow
-				page.FirstValue = firstValue.FieldByName(query.Weave.PrimaryField).Interface()
+				page.FirstValue = query.Weave.primaryValue(firstValue, false).Interface()
 				hasPrevious, err := hasPreviousQuery.Filter(query.Weave.PrimaryColumn, Ternary(direction == DESC, ">", "<"), page.FirstValue).Exists()
 				if err != nil {
 					return err
@@ -672,7 +795,7 @@ func (query *QueryStream[T]) Page(direction SortDirection, limit uint64, reverse
 				lastRow := rows[len(rows)-1]
 				lastValue := reflect.ValueOf(lastRow).Elem()
 				page.Last = lastRow
-				page.LastValue = lastValue.FieldByName(query.Weave.PrimaryField).Interface()
+				page.LastValue = query.Weave.primaryValue(lastValue, false).Interface()
 				hasNext, err := hasNextQuery.Filter(query.Weave.PrimaryColumn, Ternary(direction == DESC, "<", ">"), page.LastValue).Exists()
 				if err != nil {
 					return err
@@ -686,15 +809,15 @@ func (query *QueryStream[T]) Page(direction SortDirection, limit uint64, reverse
 }
 
 func (query *QueryStream[T]) Scan(rows *sql.Rows) (*T, error) {
-	data, err := query.ScanToMap(rows)
+	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
 	}
-	return query.Weave.ScanMap(data)
+	return query.Weave.ScanColumns(rows, columns)
 }
 
 func (query *QueryStream[T]) ScanToMap(rows *sql.Rows) (map[string]any, error) {
-	return ScanFieldsToMap(rows, query.Weave.Fields)
+	return scanDescriptorsToMap(rows, query.Weave.Descriptors)
 }
 
 func (query *QueryStream[T]) Select(columns ...any) *QueryStream[T] {
@@ -704,6 +827,43 @@ func (query *QueryStream[T]) Select(columns ...any) *QueryStream[T] {
 	return query
 }
 
+func (query *QueryStream[T]) prefetch(rows []*T) error {
+	for _, name := range query.Config.FetchRelated {
+		relation, ok := query.Weave.Relations[name]
+		if !ok {
+			if _, exists := query.Weave.Type.FieldByName(name); !exists {
+				return fmt.Errorf("trance: invalid field '%s' for fetching related. Field does not exist on model", name)
+			}
+			return fmt.Errorf("trance: invalid field '%s' for fetching related. Field must be of type trance.ForeignKey[To], trance.NullForeignKey[To], or trance.OneToMany[To]", name)
+		}
+
+		fields := make([]relationField, len(rows))
+		keys := make([]any, 0, len(rows))
+		seen := make(map[any]struct{}, len(rows))
+		for i, row := range rows {
+			fields[i] = fieldByIndex(reflect.ValueOf(row).Elem(), relation.Field.Index, true).Addr().Interface().(relationField)
+			if key, ok := fields[i].relatedKey(); ok {
+				if _, exists := seen[key]; !exists {
+					seen[key] = struct{}{}
+					keys = append(keys, key)
+				}
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		index, err := fields[0].fetchRelated(query.Config, query.dialect, relation.Column, keys)
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			field.assignRelated(index)
+		}
+	}
+	return nil
+}
+
 func (query *QueryStream[T]) slice() ([]*T, error) {
 	rows := make([]*T, 0)
 	if query.Error != nil {
@@ -711,112 +871,20 @@ func (query *QueryStream[T]) slice() ([]*T, error) {
 	}
 	defer query.Rows.Close()
 
-	relatedPks := make(map[string]relatedPk)
+	columns, err := query.Rows.Columns()
+	if err != nil {
+		return rows, err
+	}
 	for query.Rows.Next() {
-		row, err := query.Scan(query.Rows)
+		row, err := query.Weave.ScanColumns(query.Rows, columns)
 		if err != nil {
 			return rows, err
 		}
-		if len(query.Config.FetchRelated) > 0 {
-			value := reflect.ValueOf(row).Elem()
-			for _, column := range query.Config.FetchRelated {
-				valueFk := value.FieldByName(column)
-				if !valueFk.IsValid() {
-					return rows, fmt.Errorf("trance: invalid field '%s' for fetching related. Field does not exist on model", column)
-				}
-				if strings.HasPrefix(valueFk.Type().String(), "trance.ForeignKey[") || strings.HasPrefix(valueFk.Type().String(), "trance.NullForeignKey[") {
-					if valueFk.FieldByName("Valid").Interface().(bool) {
-						r := reflect.New(valueFk.Type()).MethodByName("Weave").Call(nil)
-						rpk, ok := relatedPks[column]
-						if !ok {
-							rpk = relatedPk{
-								RelatedColumn: reflect.Indirect(r[0]).FieldByName("PrimaryColumn").Interface().(string),
-								RelatedField:  reflect.Indirect(r[0]).FieldByName("PrimaryField").Interface().(string),
-								RelatedValues: make([]any, 0),
-							}
-						}
-						rpk.RelatedValues = append(rpk.RelatedValues, valueFk.FieldByName("Row").Elem().FieldByName(rpk.RelatedField).Interface())
-						relatedPks[column] = rpk
-					}
-				} else if strings.HasPrefix(valueFk.Type().String(), "trance.OneToMany[") {
-					rpk, ok := relatedPks[column]
-					if !ok {
-						relatedColumn := valueFk.FieldByName("RelatedColumn").Interface().(string)
-						r := reflect.New(valueFk.Type()).MethodByName("Weave").Call(nil)
-						fkModelFields := reflect.Indirect(r[0]).FieldByName("Fields").MapRange()
-						var relatedField string
-						for fkModelFields.Next() {
-							fkModelField := fkModelFields.Value().FieldByName("Tag").MethodByName("Get").Call([]reflect.Value{reflect.ValueOf("@")})[0].Interface().(string)
-							if fkModelField == relatedColumn {
-								relatedField = fkModelFields.Value().FieldByName("Name").Interface().(string)
-								break
-							}
-						}
-
-						if relatedField == "" {
-							return rows, fmt.Errorf("trance: invalid db tag of '%s' for fetching related on field '%s'. No fields with a matching column exist on the related model", relatedColumn, column)
-						}
-
-						rpk = relatedPk{
-							RelatedColumn: relatedColumn,
-							RelatedField:  relatedField,
-							RelatedValues: make([]any, 0),
-						}
-					}
-					rpk.RelatedValues = append(rpk.RelatedValues, value.FieldByName(query.Weave.PrimaryField).Interface())
-					relatedPks[column] = rpk
-				} else {
-					return rows, fmt.Errorf("trance: invalid field '%s' for fetching related. Field must be of type trance.ForeignKey[To], trance.NullForeignKey[To], or trance.OneToMany[To, From]", column)
-				}
-			}
-		}
 		rows = append(rows, row)
 	}
 
-	if len(relatedPks) > 0 {
-		var temp T
-		modelValue := reflect.ValueOf(&temp).Elem()
-
-		for column, rpk := range relatedPks {
-			if len(rpk.RelatedValues) > 0 {
-				fk := reflect.New(modelValue.FieldByName(column).Type())
-
-				q := fk.MethodByName("Query").Call(nil)
-				q = q[0].MethodByName("Filter").Call([]reflect.Value{
-					reflect.ValueOf(rpk.RelatedColumn),
-					reflect.ValueOf("IN"),
-					reflect.ValueOf(rpk.RelatedValues),
-				})
-				q = q[0].MethodByName("Collect").Call([]reflect.Value{})
-				rowsValue := reflect.ValueOf(rows)
-				for i := 0; i < rowsValue.Len(); i++ {
-					value := rowsValue.Index(i).Elem()
-					valueFk := value.FieldByName(column)
-
-					if strings.HasPrefix(fk.Type().String(), "*trance.OneToMany[") {
-						mq := fk.MethodByName("Weave").Call(nil)
-						fkPrimaryField := reflect.Indirect(mq[0]).FieldByName("PrimaryField").Interface().(string)
-						for j := 0; j < q[0].Len(); j++ {
-							fkRow := q[0].Index(j).Elem()
-							relatedFieldId := fkRow.FieldByName(rpk.RelatedField).FieldByName("Row").Elem().FieldByName(fkPrimaryField).Interface()
-							if value.FieldByName(query.Weave.PrimaryField).Interface() == relatedFieldId {
-								valueFk.FieldByName("Rows").Set(reflect.Append(valueFk.FieldByName("Rows"), fkRow.Addr()))
-							}
-						}
-					} else if valueFk.FieldByName("Valid").Interface().(bool) {
-						mq := fk.MethodByName("Weave").Call(nil)
-						fkPrimaryField := reflect.Indirect(mq[0]).FieldByName("PrimaryField").Interface().(string)
-						for j := 0; j < q[0].Len(); j++ {
-							fkRow := q[0].Index(j)
-							if valueFk.FieldByName("Row").Elem().FieldByName(query.Weave.PrimaryField).Interface() == fkRow.Elem().FieldByName(fkPrimaryField).Interface() {
-								valueFk.FieldByName("Row").Set(fkRow)
-								break
-							}
-						}
-					}
-				}
-			}
-		}
+	if err := query.prefetch(rows); err != nil {
+		return rows, err
 	}
 
 	if query.Config.Context != nil {
@@ -936,6 +1004,33 @@ func (query *QueryStream[T]) TableColumnAdd(column string) *QueryResultStreamer[
 	return result
 }
 
+// TableColumnAlter changes the type, nullability, and default of a column to match the model. SQLite rebuilds the
+// table. Statements run inside a transaction unless the query already has one.
+func (query *QueryStream[T]) TableColumnAlter(column string) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	queryString, err := query.dialect.BuildTableColumnAlter(query.Config, column)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExecRebuild(db, queryString)
+	return result
+}
+
 func (query *QueryStream[T]) TableColumnDrop(column string) *QueryResultStreamer[T] {
 	result := &QueryResultStreamer[T]{
 		Error:       query.Error,
@@ -961,6 +1056,31 @@ func (query *QueryStream[T]) TableColumnDrop(column string) *QueryResultStreamer
 	return result
 }
 
+func (query *QueryStream[T]) TableColumnRename(from string, to string) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	queryString, err := query.dialect.BuildTableColumnRename(query.Config, from, to)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExec(db, queryString)
+	return result
+}
+
 func (query *QueryStream[T]) TableCreate(tableCreateConfig ...TableCreateConfig) *QueryResultStreamer[T] {
 	result := &QueryResultStreamer[T]{
 		Error:       query.Error,
@@ -1019,6 +1139,86 @@ func (query *QueryStream[T]) TableDrop(tableDropConfig ...TableDropConfig) *Quer
 	return result
 }
 
+// TableForeignKeyAdd adds the foreign key constraint of a ForeignKey or NullForeignKey column on the model. SQLite
+// rebuilds the table. Statements run inside a transaction unless the query already has one.
+func (query *QueryStream[T]) TableForeignKeyAdd(column string) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	queryString, err := query.dialect.BuildTableForeignKeyAdd(query.Config, column)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExecRebuild(db, queryString)
+	return result
+}
+
+// TableForeignKeyDrop drops the foreign key constraint of a column. SQLite rebuilds the table from the model, so the
+// column must no longer be a foreign key on the model. Statements run inside a transaction unless the query already
+// has one.
+func (query *QueryStream[T]) TableForeignKeyDrop(column string) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	queryString, err := query.dialect.BuildTableForeignKeyDrop(query.Config, column)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExecRebuild(db, queryString)
+	return result
+}
+
+func (query *QueryStream[T]) TableRename(name string) *QueryResultStreamer[T] {
+	result := &QueryResultStreamer[T]{
+		Error:       query.Error,
+		WeaveConfig: query.Weave.Config,
+	}
+	if result.Error != nil {
+		return result
+	}
+
+	db := Database()
+	if db == nil {
+		result.Error = UseDatabaseError{}
+		return result
+	}
+	query.detectDialect()
+	query.configure()
+	queryString, err := query.dialect.BuildTableRename(query.Config, name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Result, result.Error = query.dbExec(db, queryString)
+	return result
+}
+
 func (query *QueryStream[T]) Transaction(transaction *sql.Tx) *QueryStream[T] {
 	query.Config.Transaction = transaction
 	return query
@@ -1120,10 +1320,12 @@ func (query *QueryStream[T]) ViewSelect(ctx context.Context) *QueryViewStream[T]
 	return result
 }
 
-type relatedPk struct {
-	RelatedColumn string
-	RelatedField  string
-	RelatedValues []any
+type IndexCreateConfig struct {
+	IfNotExists bool
+}
+
+type IndexDropConfig struct {
+	IfExists bool
 }
 
 type TableCreateConfig struct {
@@ -1,6 +1,7 @@
 package trance
 
 import (
+	"errors"
 	"sort"
 	"testing"
 
@@ -31,6 +32,35 @@ func TestQueryConfigure(t *testing.T) {
 	}
 }
 
+func TestQueryConfigureJsonContainment(t *testing.T) {
+	type testModel struct {
+		Id       int64          `@:"id" @primary:"true"`
+		Settings map[string]any `@:"settings" @json:"true"`
+		Tags     []string       `@:"tags"`
+	}
+	defer PurgeWeaves()
+
+	PurgeWeaves()
+	query := QueryWith[testModel](WeaveConfig{NoCache: true}).
+		Filter("settings", "@>", map[string]any{"theme": "dark"}).
+		Filter("tags", "@>", []string{"a"})
+	filters := query.Config.Filters
+	query.configure()
+	document, ok := query.Config.Filters[0].Right.(JSON[any])
+	if !ok {
+		t.Fatalf("Expected JSON field filter to be wrapped, got '%#v'", query.Config.Filters[0].Right)
+	}
+	if encoded, err := document.Value(); err != nil || encoded != `{"theme":"dark"}` {
+		t.Errorf(`Expected '{"theme":"dark"}', got '%#v'`, encoded)
+	}
+	if _, ok := query.Config.Filters[2].Right.([]string); !ok {
+		t.Errorf("Expected array filter to be left as a slice, got '%#v'", query.Config.Filters[2].Right)
+	}
+	if _, ok := filters[0].Right.(map[string]any); !ok {
+		t.Errorf("Expected original filters to be unchanged, got '%#v'", filters[0].Right)
+	}
+}
+
 func TestQueryDetectDialect(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -207,6 +237,54 @@ func TestQueryJoins(t *testing.T) {
 	assertJoins(t, expected, query.Config.Joins)
 }
 
+func TestQueryTableColumnAlter(t *testing.T) {
+	type testModel struct {
+		Id    int64  `@:"id" @primary:"true"`
+		Value string `@:"value"`
+	}
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testDialect{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	UseDatabase(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER|testmodel|value|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := Query[testModel]().TableColumnAlter("value").Error; err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER|testmodel|value|").WillReturnError(errors.New("failed"))
+	mock.ExpectRollback()
+	if err := Query[testModel]().TableColumnAlter("value").Error; err == nil {
+		t.Error("Expected error")
+	}
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	mock.ExpectExec("ALTER|testmodel|value|").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := Query[testModel]().Transaction(tx).TableColumnAlter("value").Error; err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 type testGroupsQuerySlice struct {
 	Accounts OneToMany[testAccountsQuerySlice] `@:"group_id"`
 	Id       int64                             `@:"id" @primary:"true"`
@@ -355,3 +433,102 @@ func TestQuerySlice(t *testing.T) {
 		}
 	}
 }
+
+func benchmarkQuerySliceSetup(b *testing.B) sqlmock.Sqlmock {
+	SetDialect(testDialect{})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatal("failed to open sqlmock database:", err)
+	}
+	b.Cleanup(func() {
+		db.Close()
+		defaultDialect = nil
+		PurgeWeaves()
+	})
+	UseDatabase(db)
+	return mock
+}
+
+func BenchmarkQuerySliceForeignKey(b *testing.B) {
+	const parents = 5000
+	const related = 500
+	mock := benchmarkQuerySliceSetup(b)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		accounts := sqlmock.NewRows([]string{"id", "name", "group_id"})
+		for j := 0; j < parents; j++ {
+			accounts.AddRow(j, "foo", j%related)
+		}
+		groups := sqlmock.NewRows([]string{"id", "name"})
+		for j := 0; j < related; j++ {
+			groups.AddRow(j, "Group")
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(accounts)
+		mock.ExpectQuery("SELECT").WillReturnRows(groups)
+		query := Query[testAccountsQuerySlice]().FetchRelated("Group")
+		query.Rows, _ = Database().Query("SELECT")
+		b.StartTimer()
+
+		if _, err := query.slice(); err != nil {
+			b.Fatal("Unexpected error:", err)
+		}
+	}
+}
+
+func BenchmarkQuerySliceOneToMany(b *testing.B) {
+	const parents = 500
+	const related = 5000
+	mock := benchmarkQuerySliceSetup(b)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		groups := sqlmock.NewRows([]string{"id", "name"})
+		for j := 0; j < parents; j++ {
+			groups.AddRow(j, "Group")
+		}
+		accounts := sqlmock.NewRows([]string{"id", "name", "group_id"})
+		for j := 0; j < related; j++ {
+			accounts.AddRow(j, "foo", j%parents)
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(groups)
+		mock.ExpectQuery("SELECT").WillReturnRows(accounts)
+		query := Query[testGroupsQuerySlice]().FetchRelated("Accounts")
+		query.Rows, _ = Database().Query("SELECT")
+		b.StartTimer()
+
+		if _, err := query.slice(); err != nil {
+			b.Fatal("Unexpected error:", err)
+		}
+	}
+}
+
+func TestQueryPageNestedPrimaryKey(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testResourceDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	mock.ExpectQuery("SELECT|testresourcenestedpost|").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT|testresourcenestedpost|").WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(1, "One").AddRow(2, "Two"))
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}))
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs(int64(2)).WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(3, "Three"))
+	page := Query[testResourceNestedPost]().Page(ASC, 2, false, nil)
+	if _, err := page.Stream.Collect(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if page.FirstValue != int64(1) || page.LastValue != int64(2) || page.HasPrevious || !page.HasNext {
+		t.Errorf("Unexpected page '%+v'", page)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
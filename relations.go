@@ -0,0 +1,103 @@
+package trance
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type RelationKind int
+
+const (
+	RelationForeignKey RelationKind = iota + 1
+	RelationNullForeignKey
+	RelationOneToMany
+)
+
+type WeaveRelation struct {
+	Column string
+	Field  reflect.StructField
+	Kind   RelationKind
+}
+
+// relationField is implemented by pointers to ForeignKey, NullForeignKey, and OneToMany. It allows prefetching to
+// collect keys, query related rows, and assign them without looking up methods through reflection for every row.
+type relationField interface {
+	assignRelated(index any)
+	fetchRelated(config QueryConfig, dialect Dialect, column string, keys []any) (any, error)
+	relatedKey() (any, bool)
+	relationKind() RelationKind
+	setRelatedKey(column string, key any) error
+}
+
+var relationFieldType = reflect.TypeOf((*relationField)(nil)).Elem()
+
+func newWeaveRelation(field reflect.StructField, column string) (WeaveRelation, bool) {
+	if !reflect.PointerTo(field.Type).Implements(relationFieldType) {
+		return WeaveRelation{}, false
+	}
+	return WeaveRelation{
+		Column: column,
+		Field:  field,
+		Kind:   reflect.New(field.Type).Interface().(relationField).relationKind(),
+	}, true
+}
+
+func relatedQuery[To any](config QueryConfig, dialect Dialect) *QueryStream[To] {
+	query := Query[To]()
+	query.Config.Context = config.Context
+	query.Config.Transaction = config.Transaction
+	query.dialect = dialect
+	return query
+}
+
+func fetchRelatedByPrimary[To any](config QueryConfig, dialect Dialect, keys []any) (any, error) {
+	weave := Use[To]()
+	rows, err := relatedQuery[To](config, dialect).Filter(weave.PrimaryColumn, "IN", keys).Collect()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[any]*To, len(rows))
+	for _, row := range rows {
+		index[primaryKeyOf(row)] = row
+	}
+	return index, nil
+}
+
+func primaryKeyOf[To any](row *To) any {
+	value := reflect.ValueOf(row).Elem()
+	return fieldByIndex(value, primaryIndex(value.Type()), false).Interface()
+}
+
+func rowWithPrimaryKey[To any](key any) (*To, error) {
+	row := new(To)
+	value := reflect.ValueOf(row).Elem()
+	index := primaryIndex(value.Type())
+	if index == nil {
+		return row, fmt.Errorf("trance: related model '%s' has no primary key", value.Type())
+	}
+	field := fieldByIndex(value, index, true)
+	keyValue, ok := convertValue(reflect.ValueOf(key), field.Type())
+	if !ok {
+		return row, fmt.Errorf("trance: unhandled type conversion in scan from '%T' to '%s'", key, field.Type())
+	}
+	field.Set(keyValue)
+	return row, nil
+}
+
+// convertValue converts between assignable and numeric types. Conversions from numbers to strings are rejected
+// because reflect treats them as rune conversions.
+func convertValue(value reflect.Value, to reflect.Type) (reflect.Value, bool) {
+	if !value.IsValid() {
+		return value, false
+	}
+	if value.Type().AssignableTo(to) {
+		return value, true
+	}
+	if to.Kind() == reflect.String && value.Kind() != reflect.String {
+		return value, false
+	}
+	if value.CanConvert(to) {
+		return value.Convert(to), true
+	}
+	return value, false
+}
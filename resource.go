@@ -0,0 +1,56 @@
+package trance
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Resource routes JSON handlers for a model under a path, such as "/posts":
+//
+//	GET    /posts       List
+//	POST   /posts       Create, responding with 201 Created and a Location header
+//	GET    /posts/{id}  Find
+//	PUT    /posts/{id}  Edit
+//	PATCH  /posts/{id}  Edit, updating only the fields present in the body
+//	DELETE /posts/{id}  Delete, responding with 204 No Content
+//
+// Records are guarded as with the individual handlers. DELETE requests have no body, so GuardForm receives a zero
+// FormDelete. Middleware applies to every route of the resource.
+func Resource[T Viewer, FormCreate any, FormDelete any, FormEdit any](router Router, path string, middleware ...func(*Strand) error) {
+	if Database() == nil {
+		panic("trance: Database not registered. Please call 'trance.UseDatabase(*sql.DB)' before 'trance.Resource[T Viewer, FormCreate any, FormDelete any, FormEdit any](Router, string)'")
+	}
+	renderer := Json[T]()
+	handleEdit := EditJson[T, FormEdit]()
+	path = strings.TrimSuffix(path, "/")
+
+	router.Get(path, ListJson[T](), middleware...)
+	router.Post(path, createHandler[T, FormCreate](renderer, true), middleware...)
+	router.Get(path+"/{id}", FindJson[T](), middleware...)
+	router.Put(path+"/{id}", handleEdit, middleware...)
+	router.Patch(path+"/{id}", handleEdit, middleware...)
+	router.Delete(path+"/{id}", deleteHandler[T, FormDelete](renderer, true), middleware...)
+}
+
+// defaultStatusWriter writes a status other than 200 OK for responses that do not set one.
+type defaultStatusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (writer *defaultStatusWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *defaultStatusWriter) Write(body []byte) (int, error) {
+	if !writer.wroteHeader {
+		writer.WriteHeader(writer.status)
+	}
+	return writer.ResponseWriter.Write(body)
+}
+
+func (writer *defaultStatusWriter) WriteHeader(status int) {
+	writer.wroteHeader = true
+	writer.ResponseWriter.WriteHeader(status)
+}
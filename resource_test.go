@@ -0,0 +1,346 @@
+package trance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/exp/slices"
+)
+
+type testResourceDialect struct {
+	testDialect
+}
+
+func (dialect testResourceDialect) BuildDelete(config QueryConfig) (string, []any, error) {
+	where, args := dialect.where(config)
+	return fmt.Sprintf("DELETE|%s|%s", config.Table, where), args, nil
+}
+
+func (dialect testResourceDialect) BuildInsert(config QueryConfig, data map[string]any, columns ...string) (string, []any, error) {
+	slices.Sort(columns)
+	args := make([]any, 0, len(columns))
+	for _, column := range columns {
+		args = append(args, data[column])
+	}
+	return fmt.Sprintf("INSERT|%s|%s", config.Table, strings.Join(columns, ",")), args, nil
+}
+
+func (dialect testResourceDialect) BuildSelect(config QueryConfig) (string, []any, error) {
+	where, args := dialect.where(config)
+	return fmt.Sprintf("SELECT|%s|%s", config.Table, where), args, nil
+}
+
+func (dialect testResourceDialect) BuildUpdate(config QueryConfig, data map[string]any, columns ...string) (string, []any, error) {
+	where, args := dialect.where(config)
+	slices.Sort(columns)
+	values := make([]any, 0, len(columns)+len(args))
+	for _, column := range columns {
+		values = append(values, data[column])
+	}
+	return fmt.Sprintf("UPDATE|%s|%s|%s", config.Table, strings.Join(columns, ","), where), append(values, args...), nil
+}
+
+func (dialect testResourceDialect) where(config QueryConfig) (string, []any) {
+	columns := make([]string, 0)
+	args := make([]any, 0)
+	for _, filter := range config.Filters {
+		if filter.Rule == "WHERE" {
+			columns = append(columns, fmt.Sprint(filter.Left))
+			args = append(args, filter.Right)
+		}
+	}
+	return strings.Join(columns, ","), args
+}
+
+type testResourcePost struct {
+	Id    int64  `@:"id" @primary:"true"`
+	Title string `@:"title"`
+}
+
+func (post testResourcePost) ViewSelect(context.Context) *View {
+	return AllowFields("*").AllowFilter("id")
+}
+
+type testResourcePostForm struct {
+	Title string `@:"title"`
+}
+
+func TestResource(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testResourceDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	app := &App{}
+	Resource[testResourcePost, testResourcePostForm, testResourcePostForm, testResourcePostForm](app.Group("/api"), "/posts/")
+
+	expected := []struct {
+		body     string
+		expect   func()
+		location string
+		method   string
+		path     string
+		request  string
+		status   int
+	}{
+		{
+			body: `{"items":[{"id":1,"title":"One"},{"id":2,"title":"Two"}]}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "One").AddRow(2, "Two"))
+			},
+			method: "GET",
+			path:   "/api/posts",
+			status: http.StatusOK,
+		},
+		{
+			body: `{"item":{"id":3,"title":"Three"}}`,
+			expect: func() {
+				mock.ExpectExec("INSERT|testresourcepost|title").WithArgs("Three").WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "Three"))
+			},
+			location: "/api/posts/3",
+			method:   "POST",
+			path:     "/api/posts",
+			request:  `{"title": "Three"}`,
+			status:   http.StatusCreated,
+		},
+		{
+			body: `{"item":{"id":1,"title":"One"}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "One"))
+			},
+			method: "GET",
+			path:   "/api/posts/1",
+			status: http.StatusOK,
+		},
+		{
+			body: `{"item":{"id":2,"title":"Two"}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("2").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "Two"))
+			},
+			method: "GET",
+			path:   "/api/posts/1?filter.id=2",
+			status: http.StatusOK,
+		},
+		{
+			body: `{"item":{"id":1,"title":"Three"}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "One"))
+				mock.ExpectExec("UPDATE|testresourcepost|title|id").WithArgs("Three", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Three"))
+			},
+			method:  "PUT",
+			path:    "/api/posts/1",
+			request: `{"title": "Three"}`,
+			status:  http.StatusOK,
+		},
+		{
+			body: `{"item":{"id":1,"title":"Four"}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Three"))
+				mock.ExpectExec("UPDATE|testresourcepost|title|id").WithArgs("Four", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Four"))
+			},
+			method:  "PATCH",
+			path:    "/api/posts/1",
+			request: `{"title": "Four"}`,
+			status:  http.StatusOK,
+		},
+		{
+			body: `{"item":{"id":1,"title":"Four"}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Four"))
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Four"))
+			},
+			method:  "PATCH",
+			path:    "/api/posts/1",
+			request: `{}`,
+			status:  http.StatusOK,
+		},
+		{
+			body: `{"errors":{"title":"This field is required."}}`,
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Four"))
+			},
+			method:  "PATCH",
+			path:    "/api/posts/1",
+			request: `{"title": null}`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			expect: func() {
+				mock.ExpectQuery("SELECT|testresourcepost|id").WithArgs("2").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "Two"))
+				mock.ExpectExec("DELETE|testresourcepost|id").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			method: "DELETE",
+			path:   "/api/posts/2",
+			status: http.StatusNoContent,
+		},
+		{
+			body:    `{"error":"Method not allowed. Must be DELETE, GET, HEAD, PATCH, PUT"}`,
+			expect:  func() {},
+			method:  "POST",
+			path:    "/api/posts/2",
+			request: `{}`,
+			status:  http.StatusMethodNotAllowed,
+		},
+	}
+	for _, e := range expected {
+		e.expect()
+		request := httptest.NewRequest(e.method, e.path, strings.NewReader(e.request))
+		if e.request != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		if recorder.Code != e.status || recorder.Body.String() != e.body {
+			t.Errorf("%s %s: expected %d '%s', got %d '%s'", e.method, e.path, e.status, e.body, recorder.Code, recorder.Body.String())
+		}
+		if location := recorder.Header().Get("Location"); location != e.location {
+			t.Errorf("%s %s: expected Location '%s', got '%s'", e.method, e.path, e.location, location)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s %s: %s", e.method, e.path, err)
+		}
+	}
+}
+
+type testResourceGuardedPost struct {
+	Id    int64  `@:"id" @primary:"true"`
+	Title string `@:"title"`
+}
+
+func (post testResourceGuardedPost) GuardForm(_ *http.Request, view *View, form any) (*View, error) {
+	if _, ok := form.(*testResourceDeleteForm); ok {
+		return nil, ErrorUnauthorized{}
+	}
+	return view, nil
+}
+
+func (post testResourceGuardedPost) ViewSelect(context.Context) *View {
+	return AllowFields("*")
+}
+
+type testResourceDeleteForm struct{}
+
+func TestResourceMount(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testResourceDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	api := &App{}
+	Resource[testResourceGuardedPost, testResourcePostForm, testResourceDeleteForm, testResourcePostForm](api, "/posts")
+	app := &App{}
+	app.Mount("/v1", api)
+
+	mock.ExpectExec("INSERT|testresourceguardedpost|title").WithArgs("Three").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery("SELECT|testresourceguardedpost|id").WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "Three"))
+	request := httptest.NewRequest("POST", "/v1/posts", strings.NewReader(`{"title": "Three"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("Expected %d, got %d '%s'", http.StatusCreated, recorder.Code, recorder.Body.String())
+	}
+	if location := recorder.Header().Get("Location"); location != "/v1/posts/3" {
+		t.Errorf("Expected Location '/v1/posts/3', got '%s'", location)
+	}
+
+	mock.ExpectQuery("SELECT|testresourceguardedpost|id").WithArgs("3").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "Three"))
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/v1/posts/3", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected %d, got %d '%s'", http.StatusUnauthorized, recorder.Code, recorder.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type testResourceKey struct {
+	Id int64 `@:"id" @primary:"true"`
+}
+
+type testResourceNestedPost struct {
+	Key   testResourceKey `@prefix:"post_"`
+	Title string          `@:"title"`
+}
+
+func (post testResourceNestedPost) ViewSelect(context.Context) *View {
+	return AllowFields("*")
+}
+
+func TestResourceNestedPrimaryKey(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testResourceDialect{})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	app := &App{}
+	Resource[testResourceNestedPost, testResourcePostForm, testResourcePostForm, testResourcePostForm](app, "/posts")
+
+	mock.ExpectExec("INSERT|testresourcenestedpost|title").WithArgs("Three").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(3, "Three"))
+	request := httptest.NewRequest("POST", "/posts", strings.NewReader(`{"title": "Three"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("Expected %d, got %d '%s'", http.StatusCreated, recorder.Code, recorder.Body.String())
+	}
+	if location := recorder.Header().Get("Location"); location != "/posts/3" {
+		t.Errorf("Expected Location '/posts/3', got '%s'", location)
+	}
+
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs("3").WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(3, "Three"))
+	mock.ExpectExec("UPDATE|testresourcenestedpost|title|post_id").WithArgs("Four", int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(3, "Four"))
+	request = httptest.NewRequest("PATCH", "/posts/3", strings.NewReader(`{"title": "Four"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected %d, got %d '%s'", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	mock.ExpectQuery("SELECT|testresourcenestedpost|post_id").WithArgs("3").WillReturnRows(sqlmock.NewRows([]string{"post_id", "title"}).AddRow(3, "Four"))
+	mock.ExpectExec("DELETE|testresourcenestedpost|post_id").WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	recorder = httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/posts/3", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected %d, got %d '%s'", http.StatusNoContent, recorder.Code, recorder.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
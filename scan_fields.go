@@ -5,51 +5,36 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
-	"strings"
-	"time"
+
+	"golang.org/x/exp/maps"
 )
 
+// ScanFieldsToMap scans the current row into a map keyed by column. Field descriptors are built for every call, so
+// QueryStream.ScanToMap, which uses the descriptors cached on the weave, is faster for scanning many rows.
 func ScanFieldsToMap(rows *sql.Rows, fields map[string]reflect.StructField) (map[string]any, error) {
+	descriptors := make(map[string]*FieldDescriptor, len(fields))
+	for column, field := range fields {
+		descriptors[column] = newFieldDescriptor(field, column)
+	}
+	return scanDescriptorsToMap(rows, descriptors)
+}
+
+// scanDescriptorsToMap scans the current row into a map keyed by column using precomputed field descriptors.
+func scanDescriptorsToMap(rows *sql.Rows, fieldDescriptors map[string]*FieldDescriptor) (map[string]any, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
 	}
 
+	descriptors := make([]*FieldDescriptor, len(columns))
 	pointers := make([]any, len(columns))
 	for i, column := range columns {
-		field, ok := fields[column]
-		if !ok {
-			return nil, fmt.Errorf("trance: column '%s' not found on struct map '%#v'", column, fields)
-		}
-		fieldType := field.Type
-		if strings.HasPrefix(fieldType.String(), "trance.ForeignKey[") || strings.HasPrefix(fieldType.String(), "trance.NullForeignKey[") {
-			fk := reflect.New(fieldType)
-			q := fk.MethodByName("Weave").Call(nil)
-			fkPrimaryField := reflect.Indirect(q[0]).FieldByName("PrimaryField").Interface().(string)
-			pointers[i] = reflect.New(reflect.Indirect(reflect.Indirect(fk).FieldByName("Row")).FieldByName(fkPrimaryField).Type()).Interface()
-			if strings.HasPrefix(fieldType.String(), "trance.NullForeignKey[") {
-				switch pointers[i].(type) {
-				case *bool:
-					pointers[i] = new(sql.NullBool)
-				case *byte:
-					pointers[i] = new(sql.NullByte)
-				case *int, *int64:
-					pointers[i] = new(sql.NullInt64)
-				case *int32:
-					pointers[i] = new(sql.NullInt32)
-				case *int8, *int16:
-					pointers[i] = new(sql.NullInt16)
-				case *float32, *float64:
-					pointers[i] = new(sql.NullFloat64)
-				case *string:
-					pointers[i] = new(sql.NullString)
-				case *time.Time:
-					pointers[i] = new(sql.NullTime)
-				}
-			}
-		} else {
-			pointers[i] = reflect.New(fieldType).Interface()
+		descriptor, ok := fieldDescriptors[column]
+		if !ok || descriptor.Kind == FieldKindOneToMany {
+			return nil, fmt.Errorf("trance: column '%s' not found on struct map '%#v'", column, maps.Keys(fieldDescriptors))
 		}
+		descriptors[i] = descriptor
+		pointers[i] = reflect.New(descriptor.ScanType).Interface()
 	}
 
 	if err := rows.Scan(pointers...); err != nil {
@@ -58,7 +43,23 @@ func ScanFieldsToMap(rows *sql.Rows, fields map[string]reflect.StructField) (map
 
 	row := make(map[string]any)
 	for i, column := range columns {
-		switch vt := reflect.ValueOf(pointers[i]).Elem().Interface().(type) {
+		pointer := reflect.ValueOf(pointers[i]).Elem()
+		if pointer.Kind() == reflect.Pointer {
+			if pointer.IsNil() {
+				row[column] = nil
+				continue
+			}
+			pointer = pointer.Elem()
+		}
+		if registered := descriptors[i].registered; registered != nil && !pointer.IsNil() {
+			scanned, err := registered.scanValue(pointer.Interface(), descriptors[i].Field.Type)
+			if err != nil {
+				return nil, err
+			}
+			row[column] = scanned.Interface()
+			continue
+		}
+		switch vt := pointer.Interface().(type) {
 		case driver.Valuer:
 			row[column], _ = vt.Value()
 		default:
@@ -0,0 +1,91 @@
+package trance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// SchemaColumn describes a column as read from a live database. Types use the vocabulary of Dialect.ColumnType, such
+// as "VARCHAR(100)", so they can be compared to models.
+type SchemaColumn struct {
+	// Default is the SQL expression of the column default, which is not escaped.
+	Default string
+	// ForeignColumn and ForeignTable are set on single column foreign keys.
+	ForeignColumn string
+	ForeignTable  string
+	Name          string
+	Nullable      bool
+	Primary       bool
+	Type          string
+	// Unique is set on columns with a single column unique constraint.
+	Unique bool
+}
+
+// SchemaTable describes a table as read from a live database. Columns are in table order.
+type SchemaTable struct {
+	Columns []SchemaColumn
+	Name    string
+}
+
+// Column returns the column with a name.
+func (table SchemaTable) Column(name string) (SchemaColumn, bool) {
+	for _, column := range table.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return SchemaColumn{}, false
+}
+
+// SchemaInspector is implemented by dialects that can read the schema of a live database.
+type SchemaInspector interface {
+	// InspectSchema returns the tables of the current database or schema, sorted by name.
+	InspectSchema(context.Context, *sql.DB) ([]SchemaTable, error)
+	// SchemaColumn returns a model field as InspectSchema would return it once created.
+	SchemaColumn(string, reflect.StructField) (SchemaColumn, error)
+}
+
+// useSchemaInspector returns the database and the default dialect as a SchemaInspector.
+func useSchemaInspector() (*sql.DB, SchemaInspector, error) {
+	db := Database()
+	if db == nil {
+		return nil, nil, UseDatabaseError{}
+	}
+	if defaultDialect == nil {
+		return nil, nil, errors.New("trance: no dialect registered. Use trance.SetDialect(dialect trance.Dialect) to register a default for SQL queries")
+	}
+	inspector, ok := defaultDialect.(SchemaInspector)
+	if !ok {
+		return nil, nil, fmt.Errorf("trance: dialect %T does not support schema inspection", defaultDialect)
+	}
+	return db, inspector, nil
+}
+
+// goFieldName converts a column name to an exported Go identifier, such as "group_id" to "GroupId".
+func goFieldName(column string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range column {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if name.Len() == 0 && unicode.IsDigit(r) {
+			name.WriteString("Column")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	if name.Len() == 0 {
+		return "Column"
+	}
+	return name.String()
+}
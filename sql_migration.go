@@ -0,0 +1,271 @@
+package trance
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SqlMigration is a migration written as SQL files. See LoadSqlMigrations.
+type SqlMigration struct {
+	// DownSql is empty for migrations without a down file, which cannot be reverted.
+	DownSql string
+	// Id is the file name without the direction and extension, such as "0001_create_accounts".
+	Id    string
+	UpSql string
+}
+
+func (migration SqlMigration) Down() error {
+	if migration.DownSql == "" {
+		return fmt.Errorf("trance: migration %s: missing down file", migration.Id)
+	}
+	return execSqlMigration(migration.DownSql)
+}
+
+func (migration SqlMigration) MigrationId() string {
+	return migration.Id
+}
+
+func (migration SqlMigration) Up() error {
+	return execSqlMigration(migration.UpSql)
+}
+
+// SplitStatementsConfig describes the SQL syntax of a dialect for SplitStatements.
+type SplitStatementsConfig struct {
+	// BackslashEscapes is set when backslashes escape quotes in strings, as in MySQL.
+	BackslashEscapes bool
+	// DollarQuotes is set when strings can be quoted with $$ or $tag$, as in Postgres.
+	DollarQuotes bool
+	// HashComments is set when # starts a line comment, as in MySQL.
+	HashComments bool
+}
+
+// StatementSplitter is implemented by dialects that split SQL files into statements for their own syntax.
+type StatementSplitter interface {
+	SplitStatements(string) []string
+}
+
+var sqlMigrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadSqlMigrations returns a migration for each pair of NNNN_name.up.sql and NNNN_name.down.sql files in a directory,
+// ordered by number. The down file is optional. Statements are split by the StatementSplitter of the default dialect,
+// and run with MigrationExec. SQL migrations have IDs, such as "0001_name", so they can be mixed with Go migrations
+// that implement MigrationIdentifier.
+func LoadSqlMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Join(errors.New("trance: failed to read migrations directory"), err)
+	}
+
+	migrations := make(map[string]*SqlMigration)
+	numbers := make(map[uint64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := sqlMigrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("trance: invalid migration file name '%s'. Must be NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		number, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("trance: invalid migration file name '%s'", entry.Name()), err)
+		}
+		id := match[1] + "_" + match[2]
+		if existing, ok := numbers[number]; ok && existing != id {
+			return nil, fmt.Errorf("trance: migrations '%s' and '%s' have the same number", existing, id)
+		}
+		numbers[number] = id
+
+		contents, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("trance: failed to read migration file '%s'", entry.Name()), err)
+		}
+		migration, ok := migrations[id]
+		if !ok {
+			migration = &SqlMigration{Id: id}
+			migrations[id] = migration
+		}
+		if match[3] == "up" {
+			migration.UpSql = string(contents)
+		} else {
+			migration.DownSql = string(contents)
+		}
+	}
+
+	sortedNumbers := make([]uint64, 0, len(numbers))
+	for number := range numbers {
+		sortedNumbers = append(sortedNumbers, number)
+	}
+	sort.Slice(sortedNumbers, func(i, j int) bool { return sortedNumbers[i] < sortedNumbers[j] })
+	sorted := make([]Migration, 0, len(sortedNumbers))
+	for _, number := range sortedNumbers {
+		migration := migrations[numbers[number]]
+		if strings.TrimSpace(migration.UpSql) == "" {
+			return nil, fmt.Errorf("trance: migration %s: missing up file", migration.Id)
+		}
+		sorted = append(sorted, *migration)
+	}
+	return sorted, nil
+}
+
+func execSqlMigration(source string) error {
+	var statements []string
+	if splitter, ok := defaultDialect.(StatementSplitter); ok {
+		statements = splitter.SplitStatements(source)
+	} else {
+		statements = SplitStatements(source, SplitStatementsConfig{})
+	}
+	for i, statement := range statements {
+		if _, err := MigrationExec(statement); err != nil {
+			return errors.Join(fmt.Errorf("trance: statement %d failed", i+1), err)
+		}
+	}
+	return nil
+}
+
+// SplitStatements splits SQL into statements on semicolons outside of strings, quoted identifiers, comments, and
+// BEGIN ... END blocks of CREATE statements, such as trigger bodies. Statements are trimmed, and statements with only
+// comments are dropped.
+func SplitStatements(source string, config SplitStatementsConfig) []string {
+	statements := make([]string, 0)
+	start := 0
+	depth := 0
+	compound := false
+	hasCode := false
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case strings.HasPrefix(source[i:], "--") || (config.HashComments && c == '#'):
+			if end := strings.IndexByte(source[i:], '\n'); end != -1 {
+				i += end + 1
+			} else {
+				i = len(source)
+			}
+
+		case strings.HasPrefix(source[i:], "/*"):
+			if end := strings.Index(source[i+2:], "*/"); end != -1 {
+				i += end + 4
+			} else {
+				i = len(source)
+			}
+
+		case c == '\'' || c == '"' || c == '`':
+			hasCode = true
+			i = skipQuoted(source, i, config.BackslashEscapes && c != '`')
+
+		case config.DollarQuotes && c == '$':
+			hasCode = true
+			tag := dollarQuoteTag(source[i:])
+			if tag == "" {
+				i++
+			} else if end := strings.Index(source[i+len(tag):], tag); end != -1 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(source)
+			}
+
+		case c == ';' && depth == 0:
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(source[start:i]))
+			}
+			compound = false
+			hasCode = false
+			i++
+			start = i
+
+		case isSqlWordByte(c):
+			end := sqlWordEnd(source, i)
+			word := strings.ToUpper(source[i:end])
+			if !hasCode {
+				compound = word == "CREATE"
+			}
+			hasCode = true
+			i = end
+			if !compound {
+				continue
+			}
+			switch word {
+			case "BEGIN":
+				depth++
+			case "CASE":
+				if depth > 0 {
+					depth++
+				}
+			case "END":
+				// END IF, END LOOP, END REPEAT, and END WHILE close blocks that are not counted.
+				next := strings.TrimLeft(source[i:], " \t\r\n")
+				nextEnd := sqlWordEnd(next, 0)
+				switch strings.ToUpper(next[:nextEnd]) {
+				case "IF", "LOOP", "REPEAT", "WHILE":
+					i = len(source) - len(next) + nextEnd
+					continue
+				case "CASE":
+					i = len(source) - len(next) + nextEnd
+				}
+				if depth > 0 {
+					depth--
+				}
+			}
+
+		default:
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				hasCode = true
+			}
+			i++
+		}
+	}
+
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(source[start:]))
+	}
+	return statements
+}
+
+// dollarQuoteTag returns the opening $tag$ at the start of s, or an empty string.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isSqlWordByte(s[i]) || (i == 1 && s[i] >= '0' && s[i] <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSqlWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// skipQuoted returns the index after the quoted string or identifier starting at i. Doubled quotes are escapes.
+func skipQuoted(source string, i int, backslashEscapes bool) int {
+	quote := source[i]
+	for i++; i < len(source); i++ {
+		switch {
+		case backslashEscapes && source[i] == '\\':
+			i++
+		case source[i] == quote:
+			if i+1 < len(source) && source[i+1] == quote {
+				i++
+			} else {
+				return i + 1
+			}
+		}
+	}
+	return len(source)
+}
+
+func sqlWordEnd(source string, i int) int {
+	for i < len(source) && (isSqlWordByte(source[i]) || source[i] == '$') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,115 @@
+package trance
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadSqlMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_accounts.up.sql":   {Data: []byte("CREATE TABLE accounts (id INTEGER);\nCREATE INDEX accounts_id_idx ON accounts (id);\n")},
+		"migrations/0001_create_accounts.down.sql": {Data: []byte("DROP TABLE accounts;")},
+		"migrations/0010_seed.up.sql":              {Data: []byte("INSERT INTO accounts (id) VALUES (1)")},
+		"migrations/0002_noop.up.sql":              {Data: []byte("SELECT 1")},
+		"migrations/README.md":                     {Data: []byte("Ignored")},
+	}
+	migrations, err := LoadSqlMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []Migration{
+		SqlMigration{
+			DownSql: "DROP TABLE accounts;",
+			Id:      "0001_create_accounts",
+			UpSql:   "CREATE TABLE accounts (id INTEGER);\nCREATE INDEX accounts_id_idx ON accounts (id);\n",
+		},
+		SqlMigration{Id: "0002_noop", UpSql: "SELECT 1"},
+		SqlMigration{Id: "0010_seed", UpSql: "INSERT INTO accounts (id) VALUES (1)"},
+	}
+	if !reflect.DeepEqual(expected, migrations) {
+		t.Errorf("Expected '%+v', got '%+v'", expected, migrations)
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"invalid name":   {"m/create.up.sql": {Data: []byte("SELECT 1")}},
+		"missing up":     {"m/0001_a.down.sql": {Data: []byte("SELECT 1")}},
+		"same number":    {"m/0001_a.up.sql": {Data: []byte("SELECT 1")}, "m/0001_b.up.sql": {Data: []byte("SELECT 1")}},
+		"missing folder": {},
+	}
+	for name, fsys := range invalid {
+		if _, err := LoadSqlMigrations(fsys, "m"); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestSqlMigration(t *testing.T) {
+	defer func() {
+		defaultDialect = nil
+		PurgeWeaves()
+	}()
+	SetDialect(testMigrationDialect{transactionalDdl: true})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	UseDatabase(db)
+
+	mock.ExpectExec("LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE|migrationlogs|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT|FILTER[]|").WillReturnRows(sqlmock.NewRows([]string{"created_at", "direction", "id", "migration_type"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE a (id INTEGER)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("-- Seed.\nINSERT INTO a (id) VALUES (1)").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE|testmigrationmodel|").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT|migrationlogs|").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrations := []Migration{
+		SqlMigration{Id: "0001_a", UpSql: "CREATE TABLE a (id INTEGER);\n-- Seed.\nINSERT INTO a (id) VALUES (1);\n"},
+		testMigrationCreate{},
+	}
+	if _, err := MigrateUp(migrations); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := (SqlMigration{Id: "0001_a"}).Down(); err == nil {
+		t.Error("Expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	expected := map[string][]string{
+		"":                                   {},
+		"SELECT 1":                           {"SELECT 1"},
+		"SELECT 1;\n\nSELECT 2;\n-- Done.\n": {"SELECT 1", "SELECT 2"},
+		"SELECT ';', \"a;b\", `c;d`; SELECT 'it''s;'": {"SELECT ';', \"a;b\", `c;d`", "SELECT 'it''s;'"},
+		"-- a;b\nSELECT 1; /* c;d */ SELECT 2":        {"-- a;b\nSELECT 1", "/* c;d */ SELECT 2"},
+		"BEGIN; SELECT 1; END;":                       {"BEGIN", "SELECT 1", "END"},
+		"CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE a SET b = CASE WHEN 1 THEN 2 END; DELETE FROM c; END; SELECT 1": {
+			"CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE a SET b = CASE WHEN 1 THEN 2 END; DELETE FROM c; END",
+			"SELECT 1",
+		},
+		"CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; CASE 1 WHEN 1 THEN SELECT 2; END CASE; END; SELECT 3": {
+			"CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; CASE 1 WHEN 1 THEN SELECT 2; END CASE; END",
+			"SELECT 3",
+		},
+	}
+	for source, statements := range expected {
+		actual := SplitStatements(source, SplitStatementsConfig{})
+		if !reflect.DeepEqual(statements, actual) {
+			t.Errorf("Expected %#v, got %#v", statements, actual)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sqlitedialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evantbyrne/trance"
+)
+
+// migrationLockTable holds a row while migrations run.
+const migrationLockTable = "migrationlock"
+
+// migrationLockInterval is how long LockMigrations waits between attempts.
+var migrationLockInterval = time.Second
+
+// migrationLockTimeout is how long LockMigrations waits for another process before giving up.
+var migrationLockTimeout = 10 * time.Minute
+
+// LockMigrations inserts a lock row, waiting while another process holds it. The row is deleted when the returned
+// function is called. A process that exits while migrating leaves the row behind, so waiting stops with an error
+// after migrationLockTimeout rather than blocking forever.
+func (dialect SqliteDialect) LockMigrations(ctx context.Context, db *sql.DB) (func() error, error) {
+	table := dialect.QuoteIdentifier(migrationLockTable)
+	id := dialect.QuoteIdentifier("id")
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" ("+id+" INTEGER PRIMARY KEY)"); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(migrationLockTimeout)
+	for {
+		result, err := db.ExecContext(ctx, "INSERT OR IGNORE INTO "+table+" ("+id+") VALUES (1)")
+		if err != nil {
+			return nil, err
+		}
+		if inserted, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if inserted > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("trance: timed out after %s waiting for the migration lock. If no migrations are running, a process exited while migrating, and the lock can be released with: DELETE FROM %s WHERE %s = 1", migrationLockTimeout, table, id)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(migrationLockInterval):
+		}
+	}
+	return func() error {
+		_, err := db.ExecContext(context.Background(), "DELETE FROM "+table+" WHERE "+id+" = 1")
+		return err
+	}, nil
+}
+
+// TransactionalDdl is true, because SQLite can roll back schema changes.
+func (dialect SqliteDialect) TransactionalDdl() bool {
+	return true
+}
+
+// SplitStatements splits SQL files into statements, keeping trigger bodies whole.
+func (dialect SqliteDialect) SplitStatements(source string) []string {
+	return trance.SplitStatements(source, trance.SplitStatementsConfig{})
+}
@@ -0,0 +1,158 @@
+package sqlitedialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+	"golang.org/x/exp/maps"
+)
+
+// execer runs statements on a connection or transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// ExecTableRebuild runs the statements of a table rebuild following
+// https://www.sqlite.org/lang_altertable.html#otheralter. Outside a transaction, foreign keys are turned off on a
+// dedicated connection, the table is rebuilt inside a new transaction, and PRAGMA foreign_key_check must pass before it
+// commits. Foreign keys cannot be turned off inside a transaction, such as that of a migration, so the rebuild is
+// refused when other tables reference the table.
+func (dialect SqliteDialect) ExecTableRebuild(ctx context.Context, db *sql.DB, config trance.QueryConfig, statements string) (sql.Result, error) {
+	table, ok := config.Table.(string)
+	if !ok {
+		return nil, fmt.Errorf("trance: rebuilding table %#v requires a table name", config.Table)
+	}
+
+	if config.Transaction != nil {
+		references, err := dialect.tableReferences(ctx, config.Transaction, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(references) > 0 {
+			return nil, fmt.Errorf("trance: rebuilding table '%s' inside a transaction would apply the ON DELETE actions of foreign keys from %s. Alter it outside a transaction, such as from a non-migration script", table, strings.Join(references, ", "))
+		}
+		return dialect.execRebuildStatements(ctx, config.Transaction, config, table, statements)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var foreignKeys bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, err
+	}
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return nil, err
+		}
+		defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := dialect.execRebuildStatements(ctx, tx, config, table, statements)
+	if err == nil {
+		err = dialect.foreignKeyCheck(ctx, tx)
+	}
+	if err != nil {
+		return nil, errors.Join(err, tx.Rollback())
+	}
+	return result, tx.Commit()
+}
+
+// execRebuildStatements runs the statements of a table rebuild. When the table lacks columns of the model, such as
+// those added in a later step, the statements are rebuilt to copy only the columns the table already has.
+func (dialect SqliteDialect) execRebuildStatements(ctx context.Context, db execer, config trance.QueryConfig, table string, statements string) (sql.Result, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columns := maps.Keys(config.Fields)
+	sort.Strings(columns)
+	copied := slices.DeleteFunc(slices.Clone(columns), func(column string) bool { return !existing[column] })
+	if len(copied) < len(columns) {
+		if statements, err = dialect.buildTableRebuild(config, copied); err != nil {
+			return nil, err
+		}
+	}
+	return dialect.execStatements(ctx, db, statements)
+}
+
+func (dialect SqliteDialect) execStatements(ctx context.Context, db execer, statements string) (sql.Result, error) {
+	var result sql.Result
+	for _, statement := range dialect.SplitStatements(statements) {
+		var err error
+		if result, err = db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// foreignKeyCheck returns an error listing the tables with rows that violate foreign keys.
+func (dialect SqliteDialect) foreignKeyCheck(ctx context.Context, db execer) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	tables := make([]string, 0)
+	for rows.Next() {
+		var table, parent string
+		var rowId sql.NullInt64
+		var index int64
+		if err := rows.Scan(&table, &rowId, &parent, &index); err != nil {
+			return err
+		}
+		tables = append(tables, fmt.Sprintf("'%s' references '%s'", table, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(tables) > 0 {
+		return fmt.Errorf("trance: foreign key check failed after rebuilding table: %s", strings.Join(tables, ", "))
+	}
+	return nil
+}
+
+// tableReferences returns the other tables with foreign keys referencing a table.
+func (dialect SqliteDialect) tableReferences(ctx context.Context, db execer, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT DISTINCT m.name FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) p WHERE m.type = 'table' AND p.\"table\" = ? AND m.name != ? ORDER BY m.name", table, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	references := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		references = append(references, "'"+name+"'")
+	}
+	return references, rows.Err()
+}
@@ -0,0 +1,194 @@
+package sqlitedialect
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/evantbyrne/trance"
+)
+
+// InspectSchema reads tables from sqlite_master and PRAGMA table_info. The table used by LockMigrations is skipped.
+func (dialect SqliteDialect) InspectSchema(ctx context.Context, db *sql.DB) ([]trance.SchemaTable, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if name != migrationLockTable {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tables := make([]trance.SchemaTable, 0, len(names))
+	for _, name := range names {
+		table, err := dialect.inspectTable(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func (dialect SqliteDialect) inspectTable(ctx context.Context, db *sql.DB, name string) (trance.SchemaTable, error) {
+	table := trance.SchemaTable{Name: name}
+	rows, err := db.QueryContext(ctx, "PRAGMA table_info("+dialect.QuoteIdentifier(name)+")")
+	if err != nil {
+		return table, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, primary int
+		var column trance.SchemaColumn
+		var columnDefault sql.NullString
+		if err := rows.Scan(&cid, &column.Name, &column.Type, &notNull, &columnDefault, &primary); err != nil {
+			return table, err
+		}
+		column.Default = columnDefault.String
+		column.Nullable = notNull == 0
+		column.Primary = primary > 0
+		column.Type = strings.ToUpper(column.Type)
+		table.Columns = append(table.Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return table, err
+	}
+	rows.Close()
+
+	if err := dialect.inspectForeignKeys(ctx, db, &table); err != nil {
+		return table, err
+	}
+	return table, dialect.inspectUnique(ctx, db, &table)
+}
+
+// inspectForeignKeys sets single column foreign keys from PRAGMA foreign_key_list.
+func (dialect SqliteDialect) inspectForeignKeys(ctx context.Context, db *sql.DB, table *trance.SchemaTable) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA foreign_key_list("+dialect.QuoteIdentifier(table.Name)+")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type foreignKey struct {
+		column        string
+		columns       int
+		foreignColumn string
+		foreignTable  string
+	}
+	foreignKeys := make(map[int]*foreignKey)
+	for rows.Next() {
+		var id, seq int
+		var column, foreignTable, match, onDelete, onUpdate string
+		var foreignColumn sql.NullString
+		if err := rows.Scan(&id, &seq, &foreignTable, &column, &foreignColumn, &onUpdate, &onDelete, &match); err != nil {
+			return err
+		}
+		if existing, ok := foreignKeys[id]; ok {
+			existing.columns++
+		} else {
+			foreignKeys[id] = &foreignKey{column: column, columns: 1, foreignColumn: foreignColumn.String, foreignTable: foreignTable}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, fk := range foreignKeys {
+		if fk.columns > 1 {
+			continue
+		}
+		for i := range table.Columns {
+			if table.Columns[i].Name == fk.column {
+				table.Columns[i].ForeignColumn = fk.foreignColumn
+				table.Columns[i].ForeignTable = fk.foreignTable
+			}
+		}
+	}
+	return nil
+}
+
+// inspectUnique sets single column unique constraints from PRAGMA index_list and PRAGMA index_info.
+func (dialect SqliteDialect) inspectUnique(ctx context.Context, db *sql.DB, table *trance.SchemaTable) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA index_list("+dialect.QuoteIdentifier(table.Name)+")")
+	if err != nil {
+		return err
+	}
+	indexes := make([]string, 0)
+	for rows.Next() {
+		var name, origin string
+		var partial, seq, unique int
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			rows.Close()
+			return err
+		}
+		if unique == 1 && origin == "u" {
+			indexes = append(indexes, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		rows, err := db.QueryContext(ctx, "PRAGMA index_info("+dialect.QuoteIdentifier(index)+")")
+		if err != nil {
+			return err
+		}
+		columns := make([]string, 0)
+		for rows.Next() {
+			var cid, seqno int
+			var name string
+			if err := rows.Scan(&seqno, &cid, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			columns = append(columns, name)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(columns) != 1 {
+			continue
+		}
+		for i := range table.Columns {
+			if table.Columns[i].Name == columns[0] {
+				table.Columns[i].Unique = true
+			}
+		}
+	}
+	return nil
+}
+
+// SchemaColumn returns a model field as InspectSchema would return it once created.
+func (dialect SqliteDialect) SchemaColumn(column string, field reflect.StructField) (trance.SchemaColumn, error) {
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return trance.SchemaColumn{}, err
+	}
+	columnType, columnNull := splitColumnType(columnType)
+	schemaColumn := trance.SchemaColumn{
+		Default:  field.Tag.Get("@default"),
+		Name:     column,
+		Nullable: columnNull != " NOT NULL",
+		Primary:  strings.HasSuffix(columnType, " PRIMARY KEY"),
+		Type:     strings.TrimSuffix(columnType, " PRIMARY KEY"),
+		Unique:   field.Tag.Get("@unique") == "true",
+	}
+	schemaColumn.ForeignTable, schemaColumn.ForeignColumn, _ = trance.ForeignKeyTarget(field)
+	return schemaColumn, nil
+}
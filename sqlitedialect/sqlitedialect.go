@@ -69,6 +69,46 @@ func (dialect SqliteDialect) BuildDelete(config trance.QueryConfig) (string, []a
 	return queryString.String(), args, nil
 }
 
+func (dialect SqliteDialect) BuildIndexCreate(config trance.QueryConfig, index trance.Index, indexCreateConfig trance.IndexCreateConfig) (string, error) {
+	var sql strings.Builder
+	sql.WriteString("CREATE ")
+	if index.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX ")
+	if indexCreateConfig.IfNotExists {
+		sql.WriteString("IF NOT EXISTS ")
+	}
+	sql.WriteString(dialect.QuoteIdentifier(index.Name))
+	sql.WriteString(" ON ")
+
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+	sql.WriteString(table)
+
+	sql.WriteString(" (")
+	sql.WriteString(dialect.buildIndexColumns(index.Columns))
+	sql.WriteString(")")
+	if index.Where != "" {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(index.Where)
+	}
+	return sql.String(), nil
+}
+
+func (dialect SqliteDialect) BuildIndexDrop(config trance.QueryConfig, name string, indexDropConfig trance.IndexDropConfig) (string, error) {
+	var sql strings.Builder
+	sql.WriteString("DROP INDEX ")
+	if indexDropConfig.IfExists {
+		sql.WriteString("IF EXISTS ")
+	}
+	sql.WriteString(dialect.QuoteIdentifier(name))
+	return sql.String(), nil
+}
+
 func (dialect SqliteDialect) BuildInsert(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := make([]any, 0)
 	var queryString strings.Builder
@@ -113,6 +153,33 @@ func (dialect SqliteDialect) BuildInsert(config trance.QueryConfig, rowMap map[s
 	return queryString.String(), args, nil
 }
 
+// buildConstraints returns table constraints to append to the column definitions of CREATE TABLE.
+func (dialect SqliteDialect) buildConstraints(config trance.QueryConfig) string {
+	var sql strings.Builder
+	for _, constraint := range config.Constraints {
+		sql.WriteString(",\n\tCONSTRAINT ")
+		sql.WriteString(dialect.QuoteIdentifier(constraint.Name))
+		if constraint.Check != "" {
+			sql.WriteString(" CHECK (")
+			sql.WriteString(constraint.Check)
+			sql.WriteString(")")
+		} else {
+			sql.WriteString(" UNIQUE (")
+			sql.WriteString(dialect.buildIndexColumns(constraint.Columns))
+			sql.WriteString(")")
+		}
+	}
+	return sql.String()
+}
+
+func (dialect SqliteDialect) buildIndexColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ",")
+}
+
 func (dialect SqliteDialect) buildJoins(config trance.QueryConfig, args []any) (string, []any, error) {
 	var queryPart strings.Builder
 	if len(config.Joins) > 0 {
@@ -253,7 +320,32 @@ func (dialect SqliteDialect) BuildTableColumnAdd(config trance.QueryConfig, colu
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, dialect.QuoteIdentifier(column), columnType), nil
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s%s", table, dialect.QuoteIdentifier(column), columnType, dialect.buildEnumCheck(column, field)), nil
+}
+
+// buildEnumCheck returns a CHECK constraint limiting enum fields to their values.
+func (dialect SqliteDialect) buildEnumCheck(column string, field reflect.StructField) string {
+	enumValues, ok := trance.EnumValues(field.Type)
+	if !ok || len(enumValues) == 0 || field.Tag.Get("@type") != "" {
+		return ""
+	}
+	quotedValues := make([]string, len(enumValues))
+	for i, enumValue := range enumValues {
+		quotedValues[i] = quoteString(enumValue)
+	}
+	return fmt.Sprintf(" CHECK (%s IN (%s))", dialect.QuoteIdentifier(column), strings.Join(quotedValues, ","))
+}
+
+func (dialect SqliteDialect) BuildTableColumnAlter(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	if field.Tag.Get("@primary") == "true" {
+		return "", fmt.Errorf("trance: altering primary key column '%s' on table '%s' is not supported", column, config.Table)
+	}
+
+	return dialect.buildTableRebuild(config, nil)
 }
 
 func (dialect SqliteDialect) BuildTableColumnDrop(config trance.QueryConfig, column string) (string, error) {
@@ -265,6 +357,16 @@ func (dialect SqliteDialect) BuildTableColumnDrop(config trance.QueryConfig, col
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, dialect.QuoteIdentifier(column)), nil
 }
 
+func (dialect SqliteDialect) BuildTableColumnRename(config trance.QueryConfig, from string, to string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, dialect.QuoteIdentifier(from), dialect.QuoteIdentifier(to)), nil
+}
+
 func (dialect SqliteDialect) BuildTableCreate(config trance.QueryConfig, tableCreateConfig trance.TableCreateConfig) (string, error) {
 	var sql strings.Builder
 	sql.WriteString("CREATE TABLE ")
@@ -295,9 +397,21 @@ func (dialect SqliteDialect) BuildTableCreate(config trance.QueryConfig, tableCr
 		sql.WriteString(dialect.QuoteIdentifier(fieldName))
 		sql.WriteString(" ")
 		sql.WriteString(columnType)
+		sql.WriteString(dialect.buildEnumCheck(fieldName, field))
 	}
+	sql.WriteString(dialect.buildConstraints(config))
 	sql.WriteString("\n)")
 
+	// Indexes are created after the table.
+	for _, index := range config.Indexes {
+		indexSql, err := dialect.BuildIndexCreate(config, index, trance.IndexCreateConfig{IfNotExists: tableCreateConfig.IfNotExists})
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(";\n")
+		sql.WriteString(indexSql)
+	}
+
 	return sql.String(), nil
 }
 
@@ -318,6 +432,86 @@ func (dialect SqliteDialect) BuildTableDrop(config trance.QueryConfig, tableDrop
 	return queryString.String(), nil
 }
 
+func (dialect SqliteDialect) BuildTableForeignKeyAdd(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	if _, _, ok := trance.ForeignKeyTarget(field); !ok {
+		return "", fmt.Errorf("trance: column '%s' on model for table '%s' is not a foreign key", column, config.Table)
+	}
+	return dialect.buildTableRebuild(config, nil)
+}
+
+// BuildTableForeignKeyDrop rebuilds the table from the model, which must no longer declare the column as a foreign key.
+func (dialect SqliteDialect) BuildTableForeignKeyDrop(config trance.QueryConfig, column string) (string, error) {
+	field, ok := config.Fields[column]
+	if !ok {
+		return "", fmt.Errorf("trance: invalid column '%s' on model for table '%s'", column, config.Table)
+	}
+	if _, _, ok := trance.ForeignKeyTarget(field); ok {
+		return "", fmt.Errorf("trance: column '%s' on model for table '%s' is still a foreign key", column, config.Table)
+	}
+	return dialect.buildTableRebuild(config, nil)
+}
+
+// buildTableRebuild recreates a table from the model to apply alterations that ALTER TABLE doesn't support, following
+// https://www.sqlite.org/lang_altertable.html#otheralter. The given columns are copied from the old table, or all
+// columns of the model when columns is nil, and no rows are copied when columns is empty. Indexes are recreated, but
+// triggers and views are not. ExecTableRebuild runs the statements so that dropping the old table does not apply the ON
+// DELETE actions of foreign keys referencing it.
+func (dialect SqliteDialect) buildTableRebuild(config trance.QueryConfig, columns []string) (string, error) {
+	table, ok := config.Table.(string)
+	if !ok {
+		return "", fmt.Errorf("trance: rebuilding table %#v requires a table name", config.Table)
+	}
+	rebuildTable := table + "__rebuild"
+	rebuildConfig := config
+	rebuildConfig.Indexes = nil
+	rebuildConfig.Table = rebuildTable
+	tableCreateSql, err := dialect.BuildTableCreate(rebuildConfig, trance.TableCreateConfig{})
+	if err != nil {
+		return "", err
+	}
+
+	if columns == nil {
+		columns = maps.Keys(config.Fields)
+		sort.Strings(columns)
+	}
+	statements := []string{tableCreateSql}
+	if len(columns) > 0 {
+		statements = append(statements, dialect.buildTableRebuildCopy(table, columns))
+	}
+	statements = append(statements,
+		fmt.Sprintf("DROP TABLE %s", dialect.QuoteIdentifier(table)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", dialect.QuoteIdentifier(rebuildTable), dialect.QuoteIdentifier(table)),
+	)
+	for _, index := range config.Indexes {
+		indexSql, err := dialect.BuildIndexCreate(config, index, trance.IndexCreateConfig{})
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, indexSql)
+	}
+	return strings.Join(statements, ";\n"), nil
+}
+
+// buildTableRebuildCopy returns the statement of a table rebuild that copies columns from the old table to the new one.
+func (dialect SqliteDialect) buildTableRebuildCopy(table string, columns []string) string {
+	quotedColumns := dialect.buildIndexColumns(columns)
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", dialect.QuoteIdentifier(table+"__rebuild"), quotedColumns, quotedColumns, dialect.QuoteIdentifier(table))
+}
+
+func (dialect SqliteDialect) BuildTableRename(config trance.QueryConfig, name string) (string, error) {
+	// TABLE
+	table, err := dialect.buildTable(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, dialect.QuoteIdentifier(name)), nil
+}
+
 func (dialect SqliteDialect) BuildUpdate(config trance.QueryConfig, rowMap map[string]any, columns ...string) (string, []any, error) {
 	args := append([]any(nil), config.Params...)
 	var queryString strings.Builder
@@ -413,6 +607,17 @@ func (dialect SqliteDialect) ColumnType(field reflect.StructField) (string, erro
 		return tagType, nil
 	}
 
+	if nullableType, ok := trance.NullableType(field.Type); ok && field.Tag.Get("@primary") != "true" {
+		// Pointers and sql.Null[T].
+		nullableField := field
+		nullableField.Type = nullableType
+		columnType, err := dialect.ColumnType(nullableField)
+		if err != nil {
+			return "", err
+		}
+		return strings.Replace(columnType, " NOT NULL", " NULL", 1), nil
+	}
+
 	fieldInstance := reflect.Indirect(reflect.New(field.Type)).Interface()
 	var columnNull string
 	var columnPrimary string
@@ -422,6 +627,27 @@ func (dialect SqliteDialect) ColumnType(field reflect.StructField) (string, erro
 		columnPrimary = " PRIMARY KEY"
 	}
 
+	if sqlType, ok := trance.RegisteredSqlType(dialect, field.Type); columnType == "" && ok {
+		// Types added with trance.RegisterType.
+		columnNull = " NOT NULL"
+		columnType = sqlType
+	}
+
+	if enumValues, ok := trance.EnumValues(field.Type); columnType == "" && ok && len(enumValues) > 0 {
+		// The CHECK constraint is added by BuildTableCreate and BuildTableColumnAdd, which know the column name.
+		columnNull = " NOT NULL"
+		columnType = "TEXT"
+	}
+
+	if columnType == "" && trance.IsJsonField(field) {
+		columnNull = " NOT NULL"
+		columnType = "TEXT"
+	}
+
+	if columnType == "" && field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+		return "", fmt.Errorf("trance: Unsupported column type: %s. Arrays are only supported by Postgres. Use the '@json' field tag to store slices as JSON", field.Type)
+	}
+
 	switch fieldInstance.(type) {
 	case bool:
 		columnNull = " NOT NULL"
@@ -451,6 +677,11 @@ func (dialect SqliteDialect) ColumnType(field reflect.StructField) (string, erro
 		columnNull = " NOT NULL"
 		columnType = "TEXT"
 
+	case trance.Decimal:
+		// NUMERIC affinity converts values to REAL or INTEGER, which loses precision and trailing zeros of the scale.
+		columnNull = " NOT NULL"
+		columnType = "TEXT"
+
 	case time.Time:
 		columnNull = " NOT NULL"
 		columnType = "DATETIME"
@@ -530,3 +761,19 @@ func (dialect SqliteDialect) QuoteIdentifier(identifier string) string {
 func QuoteIdentifier(identifier string) string {
 	return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
 }
+
+// quoteString quotes a string literal for use in DDL statements, which do not accept parameters.
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// splitColumnType splits a column definition returned by ColumnType into its type and NULL constraint.
+func splitColumnType(columnType string) (string, string) {
+	if i := strings.Index(columnType, " NOT NULL"); i > -1 {
+		return columnType[:i], " NOT NULL"
+	}
+	if i := strings.Index(columnType, " NULL"); i > -1 {
+		return columnType[:i], " NULL"
+	}
+	return columnType, ""
+}
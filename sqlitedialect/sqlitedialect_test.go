@@ -1,11 +1,14 @@
 package sqlitedialect
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/evantbyrne/trance"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -123,6 +126,50 @@ func TestBuildDelete(t *testing.T) {
 	}
 }
 
+func TestBuildIndexCreate(t *testing.T) {
+	dialect := SqliteDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "CREATE INDEX `testmodel_a_b_idx` ON `testmodel` (`a`,`b`)"
+	queryString, err := dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a", "b"}, Name: "testmodel_a_b_idx"}, trance.IndexCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = "CREATE UNIQUE INDEX IF NOT EXISTS `testmodel_a_key` ON `testmodel` (`a`) WHERE b IS NULL"
+	queryString, err = dialect.BuildIndexCreate(config, trance.Index{Columns: []string{"a"}, Name: "testmodel_a_key", Unique: true, Where: "b IS NULL"}, trance.IndexCreateConfig{IfNotExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildIndexDrop(t *testing.T) {
+	dialect := SqliteDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "DROP INDEX `testmodel_a_b_idx`"
+	queryString, err := dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	expectedSql = "DROP INDEX IF EXISTS `testmodel_a_b_idx`"
+	queryString, err = dialect.BuildIndexDrop(config, "testmodel_a_b_idx", trance.IndexDropConfig{IfExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildInsert(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -307,6 +354,41 @@ func TestBuildTableColumnAdd(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnAlter(t *testing.T) {
+	type testModel struct {
+		Id    int64   `@:"id" @primary:"true"`
+		Value *string `@:"test_value" @default:"''" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields:  weave.Fields,
+		Indexes: []trance.Index{{Columns: []string{"test_value"}, Name: "testmodel_test_value_idx"}},
+		Table:   "testmodel",
+	}
+	expectedSql := "CREATE TABLE `testmodel__rebuild` (\n" +
+		"\t`id` INTEGER PRIMARY KEY NOT NULL,\n" +
+		"\t`test_value` TEXT NULL DEFAULT ''\n" +
+		");\n" +
+		"INSERT INTO `testmodel__rebuild` (`id`,`test_value`) SELECT `id`,`test_value` FROM `testmodel`;\n" +
+		"DROP TABLE `testmodel`;\n" +
+		"ALTER TABLE `testmodel__rebuild` RENAME TO `testmodel`;\n" +
+		"CREATE INDEX `testmodel_test_value_idx` ON `testmodel` (`test_value`)"
+	queryString, err := dialect.BuildTableColumnAlter(config, "test_value")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableColumnAlter(config, "id"); err == nil {
+		t.Error("Expected error for primary key column")
+	}
+}
+
 func TestBuildTableColumnDrop(t *testing.T) {
 	dialect := SqliteDialect{}
 	config := trance.QueryConfig{Table: "testmodel"}
@@ -320,6 +402,19 @@ func TestBuildTableColumnDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableColumnRename(t *testing.T) {
+	dialect := SqliteDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "ALTER TABLE `testmodel` RENAME COLUMN `a` TO `b`"
+	queryString, err := dialect.BuildTableColumnRename(config, "a", "b")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildTableCreate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -358,6 +453,76 @@ func TestBuildTableCreate(t *testing.T) {
 	}
 }
 
+type testStatus string
+
+func (status testStatus) Values() []string {
+	return []string{"active", "it's archived"}
+}
+
+func TestBuildTableCreateEnum(t *testing.T) {
+	type testModel struct {
+		Id       int64       `@:"id" @primary:"true"`
+		Previous *testStatus `@:"previous"`
+		Status   testStatus  `@:"status"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := "CREATE TABLE `testmodel` (\n" +
+		"\t`id` INTEGER PRIMARY KEY NOT NULL,\n" +
+		"\t`previous` TEXT NULL CHECK (`previous` IN ('active','it''s archived')),\n" +
+		"\t`status` TEXT NOT NULL CHECK (`status` IN ('active','it''s archived'))\n" +
+		")"
+	queryString, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildTableCreateIndexes(t *testing.T) {
+	type testModel struct {
+		Id      int64  `@:"id" @primary:"true"`
+		GroupId int64  `@:"group_id" @index:"true"`
+		Slug    string `@:"slug" @length:"100"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Constraints: []trance.Constraint{
+			{Check: "id > 0", Name: "testmodel_check1"},
+			{Columns: []string{"group_id", "slug"}, Name: "testmodel_group_id_slug_key"},
+		},
+		Fields:  weave.Fields,
+		Indexes: weave.Indexes,
+		Table:   "testmodel",
+	}
+	expectedSql := "CREATE TABLE IF NOT EXISTS `testmodel` (\n" +
+		"\t`group_id` INTEGER NOT NULL,\n" +
+		"\t`id` INTEGER PRIMARY KEY NOT NULL,\n" +
+		"\t`slug` TEXT NOT NULL,\n" +
+		"\tCONSTRAINT `testmodel_check1` CHECK (id > 0),\n" +
+		"\tCONSTRAINT `testmodel_group_id_slug_key` UNIQUE (`group_id`,`slug`)\n" +
+		");\n" +
+		"CREATE INDEX IF NOT EXISTS `testmodel_group_id_idx` ON `testmodel` (`group_id`)"
+	queryString, err := dialect.BuildTableCreate(config, trance.TableCreateConfig{IfNotExists: true})
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildTableDrop(t *testing.T) {
 	dialect := SqliteDialect{}
 	config := trance.QueryConfig{Table: "testmodel"}
@@ -380,6 +545,78 @@ func TestBuildTableDrop(t *testing.T) {
 	}
 }
 
+func TestBuildTableForeignKey(t *testing.T) {
+	type testFkInt struct {
+		Id int64 `@:"id" @primary:"true"`
+	}
+
+	type testModel struct {
+		ForeignKey trance.ForeignKey[testFkInt] `@:"test_fk_id" @on_delete:"CASCADE"`
+		Value      int64                        `@:"test_value"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{
+		Fields: weave.Fields,
+		Table:  "testmodel",
+	}
+	expectedSql := "CREATE TABLE `testmodel__rebuild` (\n" +
+		"\t`test_fk_id` INTEGER NOT NULL REFERENCES `testfkint` (`id`) ON DELETE CASCADE,\n" +
+		"\t`test_value` INTEGER NOT NULL\n" +
+		");\n" +
+		"INSERT INTO `testmodel__rebuild` (`test_fk_id`,`test_value`) SELECT `test_fk_id`,`test_value` FROM `testmodel`;\n" +
+		"DROP TABLE `testmodel`;\n" +
+		"ALTER TABLE `testmodel__rebuild` RENAME TO `testmodel`"
+	queryString, err := dialect.BuildTableForeignKeyAdd(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+
+	if _, err := dialect.BuildTableForeignKeyAdd(config, "test_value"); err == nil {
+		t.Error("Expected error for column that isn't a foreign key")
+	}
+	if _, err := dialect.BuildTableForeignKeyDrop(config, "test_fk_id"); err == nil {
+		t.Error("Expected error for column that is still a foreign key")
+	}
+
+	expectedSql = "CREATE TABLE `testmodel__rebuild` (\n" +
+		"\t`test_fk_id` INTEGER NOT NULL,\n" +
+		"\t`test_value` INTEGER NOT NULL\n" +
+		");\n" +
+		"INSERT INTO `testmodel__rebuild` (`test_fk_id`,`test_value`) SELECT `test_fk_id`,`test_value` FROM `testmodel`;\n" +
+		"DROP TABLE `testmodel`;\n" +
+		"ALTER TABLE `testmodel__rebuild` RENAME TO `testmodel`"
+	config.Fields = map[string]reflect.StructField{
+		"test_fk_id": {Name: "ForeignKey", Tag: `@:"test_fk_id"`, Type: reflect.TypeFor[int64]()},
+		"test_value": weave.Fields["test_value"],
+	}
+	queryString, err = dialect.BuildTableForeignKeyDrop(config, "test_fk_id")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
+func TestBuildTableRename(t *testing.T) {
+	dialect := SqliteDialect{}
+	config := trance.QueryConfig{Table: "testmodel"}
+	expectedSql := "ALTER TABLE `testmodel` RENAME TO `renamed`"
+	queryString, err := dialect.BuildTableRename(config, "renamed")
+	if err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if queryString != expectedSql {
+		t.Errorf("Expected '%s', got '%s'", expectedSql, queryString)
+	}
+}
+
 func TestBuildUpdate(t *testing.T) {
 	type testModel struct {
 		Id     int64  `@:"test_id" @primary:"true"`
@@ -454,6 +691,54 @@ func TestBuildUpdate(t *testing.T) {
 	}
 }
 
+func TestInspectSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name FROM sqlite_master").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("accounts").AddRow("groups").AddRow("migrationlock"))
+	mock.ExpectQuery("PRAGMA table_info").WillReturnRows(sqlmock.NewRows([]string{"cid", "name", "type", "notnull", "dflt_value", "pk"}).
+		AddRow(0, "id", "INTEGER", 1, nil, 1).
+		AddRow(1, "name", "text", 0, "'x'", 0).
+		AddRow(2, "group_id", "INTEGER", 1, nil, 0))
+	mock.ExpectQuery("PRAGMA foreign_key_list").WillReturnRows(sqlmock.NewRows([]string{"id", "seq", "table", "from", "to", "on_update", "on_delete", "match"}).
+		AddRow(0, 0, "groups", "group_id", "id", "NO ACTION", "CASCADE", "NONE"))
+	mock.ExpectQuery("PRAGMA index_list").WillReturnRows(sqlmock.NewRows([]string{"seq", "name", "unique", "origin", "partial"}).
+		AddRow(0, "sqlite_autoindex_accounts_1", 1, "u", 0).
+		AddRow(1, "sqlite_autoindex_accounts_2", 1, "u", 0))
+	mock.ExpectQuery("PRAGMA index_info").WillReturnRows(sqlmock.NewRows([]string{"seqno", "cid", "name"}).
+		AddRow(0, 1, "name"))
+	mock.ExpectQuery("PRAGMA index_info").WillReturnRows(sqlmock.NewRows([]string{"seqno", "cid", "name"}).
+		AddRow(0, 1, "name").
+		AddRow(1, 2, "group_id"))
+	mock.ExpectQuery("PRAGMA table_info").WillReturnRows(sqlmock.NewRows([]string{"cid", "name", "type", "notnull", "dflt_value", "pk"}).
+		AddRow(0, "total", "NUMERIC(10,2)", 1, nil, 0))
+	mock.ExpectQuery("PRAGMA foreign_key_list").WillReturnRows(sqlmock.NewRows([]string{"id", "seq", "table", "from", "to", "on_update", "on_delete", "match"}))
+	mock.ExpectQuery("PRAGMA index_list").WillReturnRows(sqlmock.NewRows([]string{"seq", "name", "unique", "origin", "partial"}))
+	tables, err := SqliteDialect{}.InspectSchema(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []trance.SchemaTable{
+		{Name: "accounts", Columns: []trance.SchemaColumn{
+			{Name: "id", Primary: true, Type: "INTEGER"},
+			{Default: "'x'", Name: "name", Nullable: true, Type: "TEXT", Unique: true},
+			{ForeignColumn: "id", ForeignTable: "groups", Name: "group_id", Type: "INTEGER"},
+		}},
+		{Name: "groups", Columns: []trance.SchemaColumn{
+			{Name: "total", Type: "NUMERIC(10,2)"},
+		}},
+	}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, tables)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestColumnType(t *testing.T) {
 	type testFkInt struct {
 		Id int64 `@:"id" @primary:"true"`
@@ -489,6 +774,15 @@ func TestColumnType(t *testing.T) {
 		ForiegnKey     trance.ForeignKey[testFkString]  `@:"test_fk_id" @on_delete:"CASCADE"`
 		ForiegnKeyNull trance.NullForeignKey[testFkInt] `@:"test_fk_null_id" @on_delete:"SET NULL" @on_update:"SET NULL"`
 		Unique         string                           `@:"test_unique" @length:"255" @unique:"true"`
+		Json           trance.JSON[map[string]any]      `@:"test_json"`
+		JsonNull       *map[string]any                  `@:"test_json_null" @json:"true"`
+		JsonTag        []string                         `@:"test_json_tag" @json:"true" @default:"'[]'"`
+		GenericNull    sql.Null[int64]                  `@:"test_generic_null"`
+		PointerText    *string                          `@:"test_pointer_text" @default:"'foo'"`
+		PointerTime    *time.Time                       `@:"test_pointer_time"`
+		Decimal        trance.Decimal                   `@:"test_decimal"`
+		DecimalMoney   trance.Decimal                   `@:"test_decimal_money" @precision:"12" @scale:"2"`
+		DecimalNull    *trance.Decimal                  `@:"test_decimal_null" @precision:"8"`
 	}
 	defer trance.PurgeWeaves()
 
@@ -518,6 +812,15 @@ func TestColumnType(t *testing.T) {
 		"test_fk_id":          "TEXT NOT NULL REFERENCES `testfkstring` (`id`) ON DELETE CASCADE",
 		"test_fk_null_id":     "INTEGER NULL REFERENCES `testfkint` (`id`) ON UPDATE SET NULL ON DELETE SET NULL",
 		"test_unique":         "TEXT NOT NULL UNIQUE",
+		"test_json":           "TEXT NOT NULL",
+		"test_json_null":      "TEXT NULL",
+		"test_json_tag":       "TEXT NOT NULL DEFAULT '[]'",
+		"test_generic_null":   "INTEGER NULL",
+		"test_pointer_text":   "TEXT NULL DEFAULT 'foo'",
+		"test_pointer_time":   "DATETIME NULL",
+		"test_decimal":        "TEXT NOT NULL",
+		"test_decimal_money":  "TEXT NOT NULL",
+		"test_decimal_null":   "TEXT NULL",
 	}
 
 	dialect := SqliteDialect{}
@@ -537,6 +840,46 @@ func TestColumnType(t *testing.T) {
 	}
 }
 
+func TestColumnTypeArray(t *testing.T) {
+	type testModel struct {
+		Tags []string `@:"tags"`
+	}
+	field, _ := reflect.TypeOf(testModel{}).FieldByName("Tags")
+	if _, err := (SqliteDialect{}).ColumnType(field); err == nil {
+		t.Fatal("Expected an error for a slice column")
+	}
+}
+
+func TestColumnTypeRegistered(t *testing.T) {
+	type testNetwork struct {
+		Mask []byte
+	}
+	type testModel struct {
+		Network     testNetwork  `@:"network"`
+		NetworkJson testNetwork  `@:"network_json" @json:"true"`
+		NetworkNull *testNetwork `@:"network_null" @unique:"true"`
+	}
+	trance.RegisterType(trance.ColumnType[testNetwork]{
+		SqlTypes: map[trance.Dialect]string{SqliteDialect{}: "BLOB"},
+	})
+
+	expected := map[string]string{
+		"Network":     "BLOB NOT NULL",
+		"NetworkJson": "BLOB NOT NULL",
+		"NetworkNull": "BLOB NULL UNIQUE",
+	}
+	for name, expectedType := range expected {
+		field, _ := reflect.TypeOf(testModel{}).FieldByName(name)
+		columnType, err := (SqliteDialect{}).ColumnType(field)
+		if err != nil {
+			t.Fatalf(`dialect.ColumnType() threw error for '%#v': %s`, field, err)
+		}
+		if columnType != expectedType {
+			t.Errorf("Expected '%s', got '%s'", expectedType, columnType)
+		}
+	}
+}
+
 func TestQuoteIdentifier(t *testing.T) {
 	values := map[string]string{
 		"abc":    "`abc`",
@@ -572,3 +915,200 @@ func TestTable(t *testing.T) {
 		}
 	}
 }
+
+func TestSchemaColumn(t *testing.T) {
+	type testModel struct {
+		Id    int64          `@:"id" @primary:"true"`
+		Name  *string        `@:"name" @default:"'x'" @length:"100"`
+		Total trance.Decimal `@:"total" @precision:"10"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testModel](trance.WeaveConfig{NoCache: true})
+	expected := []trance.SchemaColumn{
+		{Name: "id", Primary: true, Type: "INTEGER"},
+		{Default: "'x'", Name: "name", Nullable: true, Type: "TEXT"},
+		{Name: "total", Type: "TEXT"},
+	}
+	for _, expectedColumn := range expected {
+		column, err := dialect.SchemaColumn(expectedColumn.Name, weave.Fields[expectedColumn.Name])
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if column != expectedColumn {
+			t.Errorf("Expected '%#v', got '%#v'", expectedColumn, column)
+		}
+	}
+}
+
+func TestLockMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	defer func(interval time.Duration) { migrationLockInterval = interval }(migrationLockInterval)
+	migrationLockInterval = time.Millisecond
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `migrationlock` (`id` INTEGER PRIMARY KEY)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT OR IGNORE INTO `migrationlock` (`id`) VALUES (1)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT OR IGNORE INTO `migrationlock` (`id`) VALUES (1)").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM `migrationlock` WHERE `id` = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+	unlock, err := SqliteDialect{}.LockMigrations(context.Background(), db)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// A lock left behind by a crashed process times out.
+	defer func(timeout time.Duration) { migrationLockTimeout = timeout }(migrationLockTimeout)
+	migrationLockTimeout = 0
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `migrationlock` (`id` INTEGER PRIMARY KEY)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT OR IGNORE INTO `migrationlock` (`id`) VALUES (1)").WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = SqliteDialect{}.LockMigrations(context.Background(), db)
+	if err == nil || err.Error() != "trance: timed out after 0s waiting for the migration lock. If no migrations are running, a process exited while migrating, and the lock can be released with: DELETE FROM `migrationlock` WHERE `id` = 1" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	source := "CREATE TRIGGER t AFTER INSERT ON a BEGIN INSERT INTO b (c) VALUES ('x;'); END;\nSELECT 1;"
+	expected := []string{
+		"CREATE TRIGGER t AFTER INSERT ON a BEGIN INSERT INTO b (c) VALUES ('x;'); END",
+		"SELECT 1",
+	}
+	if actual := (SqliteDialect{}).SplitStatements(source); !slices.Equal(expected, actual) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestExecTableRebuild(t *testing.T) {
+	type testRebuildParent struct {
+		Id   int64  `@:"id" @primary:"true"`
+		Name string `@:"name"`
+	}
+	type testRebuildChild struct {
+		Id     int64                                `@:"id" @primary:"true"`
+		Parent trance.ForeignKey[testRebuildParent] `@:"parent_id" @on_delete:"CASCADE"`
+	}
+	defer trance.PurgeWeaves()
+
+	dialect := SqliteDialect{}
+	weave := trance.UseWith[testRebuildParent](trance.WeaveConfig{NoCache: true})
+	trance.UseWith[testRebuildChild](trance.WeaveConfig{NoCache: true})
+	config := trance.QueryConfig{Fields: weave.Fields, Table: "testrebuildparent"}
+	statements, err := dialect.BuildTableColumnAlter(config, "name")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	referencesSql := "SELECT DISTINCT m.name FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) p WHERE m.type = 'table' AND p.\"table\" = ? AND m.name != ? ORDER BY m.name"
+	columnsSql := "SELECT name FROM pragma_table_info(?)"
+	expectRebuild := func() {
+		mock.ExpectQuery(columnsSql).WithArgs("testrebuildparent").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("id").AddRow("name"))
+		mock.ExpectExec("CREATE TABLE `testrebuildparent__rebuild` (\n\t`id` INTEGER PRIMARY KEY NOT NULL,\n\t`name` TEXT NOT NULL\n)").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO `testrebuildparent__rebuild` (`id`,`name`) SELECT `id`,`name` FROM `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DROP TABLE `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ALTER TABLE `testrebuildparent__rebuild` RENAME TO `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	// Inside a transaction, a table referenced by a cascading child table is not rebuilt.
+	mock.ExpectBegin()
+	mock.ExpectQuery(referencesSql).WithArgs("testrebuildparent", "testrebuildparent").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("testrebuildchild"))
+	mock.ExpectRollback()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	txConfig := config
+	txConfig.Transaction = tx
+	_, err = dialect.ExecTableRebuild(context.Background(), db, txConfig, statements)
+	if err == nil || err.Error() != "trance: rebuilding table 'testrebuildparent' inside a transaction would apply the ON DELETE actions of foreign keys from 'testrebuildchild'. Alter it outside a transaction, such as from a non-migration script" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	tx.Rollback()
+
+	// Without references, the rebuild runs inside the transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery(referencesSql).WithArgs("testrebuildparent", "testrebuildparent").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	expectRebuild()
+	mock.ExpectCommit()
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	txConfig.Transaction = tx
+	if _, err := dialect.ExecTableRebuild(context.Background(), db, txConfig, statements); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	tx.Commit()
+
+	// Outside a transaction, foreign keys are turned off during the rebuild and checked before committing.
+	mock.ExpectQuery("PRAGMA foreign_keys").WillReturnRows(sqlmock.NewRows([]string{"foreign_keys"}).AddRow(1))
+	mock.ExpectExec("PRAGMA foreign_keys = OFF").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	expectRebuild()
+	mock.ExpectQuery("PRAGMA foreign_key_check").WillReturnRows(sqlmock.NewRows([]string{"table", "rowid", "parent", "fkid"}))
+	mock.ExpectCommit()
+	mock.ExpectExec("PRAGMA foreign_keys = ON").WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := dialect.ExecTableRebuild(context.Background(), db, config, statements); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+
+	// Violations found by the foreign key check roll back the rebuild.
+	mock.ExpectQuery("PRAGMA foreign_keys").WillReturnRows(sqlmock.NewRows([]string{"foreign_keys"}).AddRow(1))
+	mock.ExpectExec("PRAGMA foreign_keys = OFF").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	expectRebuild()
+	mock.ExpectQuery("PRAGMA foreign_key_check").WillReturnRows(sqlmock.NewRows([]string{"table", "rowid", "parent", "fkid"}).AddRow("testrebuildchild", 1, "testrebuildparent", 0))
+	mock.ExpectRollback()
+	mock.ExpectExec("PRAGMA foreign_keys = ON").WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = dialect.ExecTableRebuild(context.Background(), db, config, statements)
+	if err == nil || err.Error() != "trance: foreign key check failed after rebuilding table: 'testrebuildchild' references 'testrebuildparent'" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Columns of the model that the table lacks are not copied.
+	mock.ExpectQuery("PRAGMA foreign_keys").WillReturnRows(sqlmock.NewRows([]string{"foreign_keys"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(columnsSql).WithArgs("testrebuildparent").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("id"))
+	mock.ExpectExec("CREATE TABLE `testrebuildparent__rebuild` (\n\t`id` INTEGER PRIMARY KEY NOT NULL,\n\t`name` TEXT NOT NULL\n)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `testrebuildparent__rebuild` (`id`) SELECT `id` FROM `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DROP TABLE `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ALTER TABLE `testrebuildparent__rebuild` RENAME TO `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("PRAGMA foreign_key_check").WillReturnRows(sqlmock.NewRows([]string{"table", "rowid", "parent", "fkid"}))
+	mock.ExpectCommit()
+	if _, err := dialect.ExecTableRebuild(context.Background(), db, config, statements); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+
+	// No rows are copied when the table has none of the columns of the model.
+	mock.ExpectQuery("PRAGMA foreign_keys").WillReturnRows(sqlmock.NewRows([]string{"foreign_keys"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(columnsSql).WithArgs("testrebuildparent").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("other"))
+	mock.ExpectExec("CREATE TABLE `testrebuildparent__rebuild` (\n\t`id` INTEGER PRIMARY KEY NOT NULL,\n\t`name` TEXT NOT NULL\n)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DROP TABLE `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ALTER TABLE `testrebuildparent__rebuild` RENAME TO `testrebuildparent`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("PRAGMA foreign_key_check").WillReturnRows(sqlmock.NewRows([]string{"table", "rowid", "parent", "fkid"}))
+	mock.ExpectCommit()
+	if _, err := dialect.ExecTableRebuild(context.Background(), db, config, statements); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -11,6 +11,15 @@ type Strand struct {
 	Context  context.Context
 	Error    error
 	Response http.ResponseWriter
+
+	// finally holds functions App runs, latest first, once ErrorHandler has answered any error. It is nil for strands
+	// created outside App.
+	finally []func()
+}
+
+// PathValue returns the value of a wildcard in the route pattern, such as id in "/posts/{id}".
+func (strand *Strand) PathValue(name string) string {
+	return strand.Request().PathValue(name)
 }
 
 func (strand *Strand) Redirect(url string) error {
@@ -27,6 +36,12 @@ func (strand *Strand) Request() *http.Request {
 	return strand.Context.Value("Request").(*http.Request)
 }
 
+// RequestId returns the ID assigned to the request by AssignRequestId, or an empty string.
+func (strand *Strand) RequestId() string {
+	id, _ := strand.Context.Value(requestIdKey{}).(string)
+	return id
+}
+
 func (strand *Strand) WriteHtml(body any) error {
 	strand.Response.Header().Set("Content-Type", "text/html")
 	switch bv := body.(type) {
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.833
+// templ: version: v0.3.865
 package forms
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -20,6 +20,7 @@ type FormTemplateData struct {
 	Fields     []string
 	FieldTypes map[string]reflect.StructField
 	Method     string
+	Options    map[string][]WidgetOption
 	Values     map[string]any
 	ValuesMap  map[string]any
 	Widgets    map[string]Widgeter
@@ -66,7 +67,7 @@ func Form(formData FormTemplateData) templ.Component {
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(formData.Method)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/forms/form.templ`, Line: 35, Col: 26}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/forms/form.templ`, Line: 36, Col: 26}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
@@ -109,6 +110,11 @@ func Form(formData FormTemplateData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
+			} else if options, ok := formData.Options[key]; ok {
+				templ_7745c5c3_Err = OptionsField(fieldData, options).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
 			} else if strings.HasPrefix(fieldType, "trance.ForeignKey[") || strings.HasPrefix(fieldType, "trance.NullForeignKey[") {
 				templ_7745c5c3_Err = ForeignKeyField(fieldData).Render(ctx, templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
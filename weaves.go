@@ -1,105 +1,241 @@
 package trance
 
 import (
+	"bytes"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
+	"mime"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
-	"time"
 )
 
 type Weave[T any] struct {
 	Config        WeaveConfig
+	Constraints   []Constraint
+	Descriptors   map[string]*FieldDescriptor
 	Fields        map[string]reflect.StructField
+	Indexes       []Index
 	PrimaryColumn string
 	PrimaryField  string
+	Relations     map[string]WeaveRelation
 	Table         string
 	Type          reflect.Type
 }
 
 func (weave *Weave[T]) Clone() *Weave[T] {
 	return &Weave[T]{
+		Constraints:   slices.Clone(weave.Constraints),
+		Descriptors:   maps.Clone(weave.Descriptors),
 		Fields:        maps.Clone(weave.Fields),
+		Indexes:       slices.Clone(weave.Indexes),
 		PrimaryColumn: weave.PrimaryColumn,
 		PrimaryField:  weave.PrimaryField,
+		Relations:     maps.Clone(weave.Relations),
 		Table:         weave.Table,
 		Type:          weave.Type,
 	}
 }
 
+func (weave *Weave[T]) bindOneToMany(value reflect.Value) error {
+	for _, descriptor := range weave.Descriptors {
+		if descriptor.Kind == FieldKindOneToMany {
+			var pk any
+			if primary, ok := weave.Descriptors[weave.PrimaryColumn]; ok {
+				pk = fieldByIndex(value, primary.Index, false).Interface()
+			}
+			oneToMany := fieldByIndex(value, descriptor.Index, true).Addr().Interface().(relationField)
+			if err := oneToMany.setRelatedKey(descriptor.Column, pk); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// primaryValue returns the primary key field of a row value, which may be in an embedded or nested struct, or an invalid
+// value when the model has no primary key. Nil pointers to embedded or nested structs are allocated when alloc is true.
+func (weave *Weave[T]) primaryValue(value reflect.Value, alloc bool) reflect.Value {
+	descriptor, ok := weave.Descriptors[weave.PrimaryColumn]
+	if !ok {
+		return reflect.Value{}
+	}
+	return fieldByIndex(value, descriptor.Index, alloc)
+}
+
+// ScanColumns scans the current row directly into a new model using precomputed field descriptors. The columns must
+// match the result set, as returned by sql.Rows.Columns.
+func (weave *Weave[T]) ScanColumns(rows *sql.Rows, columns []string) (*T, error) {
+	var row T
+	value := reflect.ValueOf(&row).Elem()
+
+	pointers := make([]any, len(columns))
+	for i, column := range columns {
+		descriptor, ok := weave.Descriptors[column]
+		if !ok || descriptor.Kind == FieldKindOneToMany {
+			return nil, fmt.Errorf("trance: column '%s' not found on struct map '%#v'", column, weave.Fields)
+		}
+		switch descriptor.Kind {
+		case FieldKindArray:
+			pointers[i] = Array(fieldByIndex(value, descriptor.Index, true).Addr().Interface())
+		case FieldKindForeignKey, FieldKindNullForeignKey, FieldKindJson, FieldKindRegistered:
+			pointers[i] = reflect.New(descriptor.ScanType).Interface()
+		default:
+			pointers[i] = fieldByIndex(value, descriptor.Index, true).Addr().Interface()
+		}
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	for i, column := range columns {
+		descriptor := weave.Descriptors[column]
+		if descriptor.Kind == FieldKindJson {
+			target := fieldByIndex(value, descriptor.Index, true).Addr().Interface()
+			if err := unmarshalJsonColumn(*pointers[i].(*[]byte), target); err != nil {
+				return nil, err
+			}
+		} else if descriptor.Kind == FieldKindRegistered {
+			src := *pointers[i].(*any)
+			if src == nil {
+				continue
+			}
+			scanned, err := descriptor.registered.scanValue(src, descriptor.Field.Type)
+			if err != nil {
+				return nil, err
+			}
+			fieldByIndex(value, descriptor.Index, true).Set(scanned)
+		} else if descriptor.Kind == FieldKindForeignKey || descriptor.Kind == FieldKindNullForeignKey {
+			keyValue := reflect.ValueOf(pointers[i]).Elem()
+			if keyValue.Kind() == reflect.Pointer {
+				if keyValue.IsNil() {
+					continue
+				}
+				keyValue = keyValue.Elem()
+			}
+			key := keyValue.Interface()
+			if valuer, ok := key.(driver.Valuer); ok {
+				key, _ = valuer.Value()
+			}
+			if key == nil {
+				continue
+			}
+			fk := fieldByIndex(value, descriptor.Index, true).Addr().Interface().(relationField)
+			if err := fk.setRelatedKey(column, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := weave.bindOneToMany(value); err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
 func (weave *Weave[T]) ScanMap(data map[string]any) (*T, error) {
 	var row T
 	value := reflect.ValueOf(&row).Elem()
 
 	for column, v := range data {
-		if field, ok := weave.Fields[column]; ok {
-			if field := value.FieldByName(field.Name); field.IsValid() {
-				columnValue := reflect.ValueOf(v)
-
-				if v == nil {
-					// database/sql null types (NullString, etc) default to `Valid: false`.
-					// trance.ForeignKey and trance.NullForeignKey also follow this convention.
-
-				} else if columnValue.CanConvert(field.Type()) {
-					field.Set(columnValue)
-
-				} else if field.Kind() == reflect.Struct {
-					if scanner, ok := reflect.New(field.Type()).Interface().(sql.Scanner); ok {
-						scanner.Scan(v)
-						field.Set(reflect.ValueOf(scanner).Elem())
-
-					} else if strings.HasPrefix(field.Type().String(), "trance.ForeignKey[") || strings.HasPrefix(field.Type().String(), "trance.NullForeignKey[") {
-						subModelQ := field.Addr().MethodByName("Weave").Call(nil)
-						subPrimaryField := reflect.Indirect(subModelQ[0]).FieldByName("PrimaryField").Interface().(string)
-						subField := field.FieldByName("Row").Elem().FieldByName(subPrimaryField)
-						if subField.IsValid() {
-							// TODO: Handle primary keys that are nullable types
-							subField.Set(columnValue)
-							field.FieldByName("Valid").SetBool(true)
-						}
-					} else {
-						return nil, fmt.Errorf("trance: unhandled struct conversion in scan from '%s' to '%s'", columnValue.Type(), field.Type())
-					}
+		descriptor, ok := weave.Descriptors[column]
+		if !ok || descriptor.Kind == FieldKindOneToMany || v == nil {
+			// database/sql null types (NullString, etc) default to `Valid: false`.
+			// trance.ForeignKey and trance.NullForeignKey also follow this convention.
+			continue
+		}
+		field := fieldByIndex(value, descriptor.Index, true)
+		columnValue := reflect.ValueOf(v)
+
+		switch descriptor.Kind {
+		case FieldKindForeignKey, FieldKindNullForeignKey:
+			fk := field.Addr().Interface().(relationField)
+			if err := fk.setRelatedKey(column, v); err != nil {
+				return nil, err
+			}
 
-				} else {
-					return nil, fmt.Errorf("trance: unhandled type conversion in scan from '%s' to '%s'", columnValue.Type(), field.Type())
+		case FieldKindJson:
+			if converted, ok := convertValue(columnValue, field.Type()); ok {
+				field.Set(converted)
+			} else if err := unmarshalJsonColumn(v, field.Addr().Interface()); err != nil {
+				return nil, err
+			}
+
+		case FieldKindRegistered:
+			scanned, err := descriptor.registered.scanValue(v, field.Type())
+			if err != nil {
+				return nil, err
+			}
+			field.Set(scanned)
+
+		case FieldKindArray:
+			if converted, ok := convertValue(columnValue, field.Type()); ok && columnValue.Kind() == reflect.Slice {
+				field.Set(converted)
+			} else if err := unmarshalArrayColumn(v, field.Addr().Interface()); err != nil {
+				return nil, err
+			}
+
+		default:
+			if field.Kind() == reflect.Pointer && !columnValue.Type().AssignableTo(field.Type()) {
+				// Nullable pointer fields are set through their element.
+				field.Set(reflect.New(field.Type().Elem()))
+				field = field.Elem()
+			}
+			if scanner, ok := field.Addr().Interface().(sql.Scanner); ok && !columnValue.Type().AssignableTo(field.Type()) {
+				if err := scanner.Scan(v); err != nil {
+					return nil, err
 				}
+			} else if converted, ok := convertValue(columnValue, field.Type()); ok {
+				field.Set(converted)
+			} else if field.Kind() == reflect.Struct {
+				return nil, fmt.Errorf("trance: unhandled struct conversion in scan from '%s' to '%s'", columnValue.Type(), field.Type())
+			} else {
+				return nil, fmt.Errorf("trance: unhandled type conversion in scan from '%s' to '%s'", columnValue.Type(), field.Type())
 			}
 		}
 	}
 
-	// OneToMany relationships.
-	for _, field := range weave.Fields {
-		if strings.HasPrefix(field.Type.String(), "trance.OneToMany[") {
-			oneToMany := value.FieldByName(field.Name)
-			oneToMany.FieldByName("RelatedColumn").SetString(field.Tag.Get("@"))
-			oneToMany.FieldByName("RowPk").Set(value.FieldByName(weave.PrimaryField))
-		}
+	if err := weave.bindOneToMany(value); err != nil {
+		return nil, err
 	}
 
 	return &row, nil
 }
 
 func (weave *Weave[T]) ToJsonMap(row *T) map[string]any {
-	result := make(map[string]any, 0)
+	result := make(map[string]any, len(weave.Descriptors))
 	value := reflect.ValueOf(row).Elem()
-	for _, field := range weave.Fields {
-		fieldName := strings.ToLower(field.Name)
-		switch fv := value.FieldByName(field.Name).Interface().(type) {
+	for _, descriptor := range weave.Descriptors {
+		field := fieldByIndex(value, descriptor.Index, false)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				result[descriptor.JsonKey] = nil
+				continue
+			}
+			field = field.Elem()
+		}
+		if descriptor.registered != nil && descriptor.registered.json != nil {
+			result[descriptor.JsonKey] = descriptor.registered.json(field.Interface())
+			continue
+		}
+		switch fv := field.Interface().(type) {
 		case JsonValuer:
-			result[fieldName] = fv.JsonValue()
+			result[descriptor.JsonKey] = fv.JsonValue()
 
 		case driver.Valuer:
-			result[fieldName], _ = fv.Value()
+			result[descriptor.JsonKey], _ = fv.Value()
 
 		default:
-			result[fieldName] = fv
+			result[descriptor.JsonKey] = fv
 		}
 	}
 
@@ -107,46 +243,71 @@ func (weave *Weave[T]) ToJsonMap(row *T) map[string]any {
 }
 
 func (weave *Weave[T]) ToMap(row *T) (map[string]any, error) {
-	args := make(map[string]any)
-	value := reflect.ValueOf(*row)
+	args := make(map[string]any, len(weave.Descriptors))
+	value := reflect.ValueOf(row).Elem()
 
-	for column := range weave.Fields {
-		fieldName := weave.Fields[column].Name
-		field := value.FieldByName(fieldName)
+	for column, descriptor := range weave.Descriptors {
+		field := fieldByIndex(value, descriptor.Index, false)
 
 		// Skip zero valued primary keys.
-		if field.IsZero() && weave.Fields[column].Tag.Get("@primary") == "true" {
+		if descriptor.Primary && field.IsZero() {
 			continue
 		}
 
-		switch field.Kind() {
-		case reflect.Struct:
-			switch vv := field.Interface().(type) {
-			case driver.Valuer:
-				v, _ := vv.Value()
-				args[column] = v
+		switch descriptor.Kind {
+		case FieldKindValuer:
+			args[column], _ = field.Interface().(driver.Valuer).Value()
 
-			case time.Time:
-				args[column] = vv
-
-			default:
-				if strings.HasPrefix(field.Type().String(), "trance.ForeignKey[") || strings.HasPrefix(field.Type().String(), "trance.NullForeignKey[") {
-					if !field.FieldByName("Valid").Interface().(bool) {
-						args[column] = nil
-					} else {
-						q := reflect.New(field.Type()).MethodByName("Weave").Call(nil)
-						fkPrimaryField := reflect.Indirect(q[0]).FieldByName("PrimaryField").Interface().(string)
-						args[column] = reflect.Indirect(field.FieldByName("Row")).FieldByName(fkPrimaryField).Interface()
-					}
-				} else if strings.HasPrefix(field.Type().String(), "trance.OneToMany[") {
-					continue
-				} else {
-					return nil, fmt.Errorf("trance: unsupported field type '%s' for column '%s' on table '%s'", field.Type().String(), column, weave.Table)
+		case FieldKindForeignKey, FieldKindNullForeignKey:
+			key, ok := field.Addr().Interface().(relationField).relatedKey()
+			if ok {
+				args[column] = key
+			} else {
+				args[column] = nil
+			}
+
+		case FieldKindJson:
+			v, err := marshalJsonColumn(field)
+			if err != nil {
+				return nil, err
+			}
+			args[column] = v
+
+		case FieldKindArray:
+			if field.IsNil() {
+				// Array columns are NOT NULL, so nil slices are written as empty arrays.
+				args[column] = Array(reflect.MakeSlice(field.Type(), 0, 0).Interface())
+			} else {
+				args[column] = Array(field.Interface())
+			}
+
+		case FieldKindRegistered:
+			if descriptor.registered.value != nil {
+				v, err := descriptor.registered.value(field.Interface())
+				if err != nil {
+					return nil, err
 				}
+				args[column] = v
+			} else {
+				args[column] = field.Interface()
 			}
 
+		case FieldKindOneToMany:
+			continue
+
+		case FieldKindUnsupported:
+			return nil, fmt.Errorf("trance: unsupported field type '%s' for column '%s' on table '%s'", field.Type().String(), column, weave.Table)
+
 		default:
-			args[column] = field.Interface()
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					args[column] = nil
+				} else {
+					args[column] = field.Elem().Interface()
+				}
+			} else {
+				args[column] = field.Interface()
+			}
 		}
 	}
 
@@ -154,17 +315,17 @@ func (weave *Weave[T]) ToMap(row *T) (map[string]any, error) {
 }
 
 func (weave *Weave[T]) ToValuesMap(row *T) map[string]any {
-	result := make(map[string]any, 0)
+	result := make(map[string]any, len(weave.Descriptors))
 	value := reflect.ValueOf(row).Elem()
-	for column, field := range weave.Fields {
-		result[column] = value.FieldByName(field.Name).Interface()
+	for column, descriptor := range weave.Descriptors {
+		result[column] = fieldByIndex(value, descriptor.Index, false).Interface()
 	}
 	return result
 }
 
 func (weave *Weave[T]) Validate(r *http.Request) (*T, error) {
 	// TODO: Support TOML, YAML, XML, and gRPC content types.
-	switch r.Header.Get("Content-Type") {
+	switch requestMediaType(r) {
 	case "application/x-www-form-urlencoded", "multipart/form-data":
 		return weave.ValidateEncoded(r)
 	case "application/json":
@@ -173,6 +334,13 @@ func (weave *Weave[T]) Validate(r *http.Request) (*T, error) {
 	return nil, ErrorBadRequest{Message: "Unsupported content type"}
 }
 
+// requestMediaType returns the media type of a request body without parameters, such as the boundary of
+// "multipart/form-data; boundary=x".
+func requestMediaType(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType
+}
+
 func (weave *Weave[T]) ValidateEncoded(r *http.Request) (*T, error) {
 	errorsMap := make(map[string]error, 0)
 	value := make(map[string]any, 0)
@@ -180,7 +348,9 @@ func (weave *Weave[T]) ValidateEncoded(r *http.Request) (*T, error) {
 	for column := range weave.Fields {
 		fv := r.FormValue(column)
 		if fv == "" {
-			errorsMap[column] = errors.New("This field is required.")
+			if descriptor, ok := weave.Descriptors[column]; !ok || !descriptor.Nullable {
+				errorsMap[column] = errors.New("This field is required.")
+			}
 		} else {
 			value[column] = fv
 		}
@@ -189,6 +359,8 @@ func (weave *Weave[T]) ValidateEncoded(r *http.Request) (*T, error) {
 	record, err := weave.ScanMap(value)
 	if err != nil {
 		errorsMap["_global_"] = err
+	} else {
+		weave.validateEnums(record, errorsMap)
 	}
 
 	if len(errorsMap) > 0 {
@@ -201,6 +373,45 @@ func (weave *Weave[T]) ValidateEncoded(r *http.Request) (*T, error) {
 	return record, nil
 }
 
+// ValidateEncodedPartial validates the fields present in a form encoded body, for partial updates. It returns the
+// present columns as ToMap would, so that absent fields are distinguished from empty ones.
+func (weave *Weave[T]) ValidateEncodedPartial(r *http.Request) (map[string]any, error) {
+	var err error
+	if requestMediaType(r) == "multipart/form-data" {
+		err = r.ParseMultipartForm(32 << 20)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return nil, ErrorBadRequest{Message: err.Error()}
+	}
+
+	errorsMap := make(map[string]error, 0)
+	present := make([]string, 0)
+	value := make(map[string]any, 0)
+	for column := range weave.Fields {
+		if !r.PostForm.Has(column) {
+			continue
+		}
+		present = append(present, column)
+		if fv := r.PostForm.Get(column); fv == "" {
+			if descriptor, ok := weave.Descriptors[column]; !ok || !descriptor.Nullable {
+				errorsMap[column] = errors.New("This field is required.")
+			}
+		} else {
+			value[column] = fv
+		}
+	}
+
+	record, err := weave.ScanMap(value)
+	if err != nil {
+		errorsMap["_global_"] = err
+	} else {
+		weave.validateEnums(record, errorsMap)
+	}
+	return weave.validatedPartial(record, present, errorsMap)
+}
+
 func (weave *Weave[T]) ValidateJson(r *http.Request) (*T, error) {
 	var unmarshalErr *json.UnmarshalTypeError
 	var record T
@@ -231,12 +442,17 @@ func (weave *Weave[T]) ValidateJson(r *http.Request) (*T, error) {
 	value := reflect.ValueOf(record)
 	for column := range weave.Fields {
 		if _, exists := errorsMap[column]; !exists {
-			if value.FieldByName(weave.Fields[column].Name).IsZero() {
+			if descriptor, ok := weave.Descriptors[column]; ok && descriptor.Nullable {
+				continue
+			}
+			if fieldByIndex(value, weave.Fields[column].Index, false).IsZero() {
 				errorsMap[column] = errors.New("This field is required.")
 			}
 		}
 	}
 
+	weave.validateEnums(&record, errorsMap)
+
 	if len(errorsMap) > 0 {
 		return &record, FormErrors{
 			Errors:     errorsMap,
@@ -247,6 +463,123 @@ func (weave *Weave[T]) ValidateJson(r *http.Request) (*T, error) {
 	return &record, nil
 }
 
+// ValidateJsonPartial validates the fields present in a JSON body, for partial updates. It returns the present columns
+// as ToMap would, so that absent fields are distinguished from zero values such as false or 0.
+func (weave *Weave[T]) ValidateJsonPartial(r *http.Request) (map[string]any, error) {
+	var unmarshalErr *json.UnmarshalTypeError
+	var record T
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, ErrorBadRequest{Message: err.Error()}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		// A missing body updates no fields.
+		body = []byte("{}")
+	}
+	errorsMap := make(map[string]error, 0)
+	raw := make(map[string]json.RawMessage, 0)
+	if err := json.Unmarshal(body, &raw); err != nil {
+		errorsMap["_global_"] = err
+		return nil, FormErrors{
+			Errors:     errorsMap,
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	paths := make(map[string][]string, len(weave.Fields))
+	for column, field := range weave.Fields {
+		if path := jsonPath(weave.Type, field.Index); path != nil {
+			paths[column] = path
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		errorsMap["_global_"] = err
+		if errors.As(err, &unmarshalErr) {
+			for column, path := range paths {
+				if strings.EqualFold(strings.Join(path, "."), unmarshalErr.Field) {
+					delete(errorsMap, "_global_")
+					errorsMap[column] = errors.New("Wrong type provided.")
+					break
+				}
+			}
+		}
+	}
+
+	present := make([]string, 0)
+	for column, path := range paths {
+		value, ok := jsonLookup(raw, path)
+		if !ok {
+			continue
+		}
+		present = append(present, column)
+		if _, exists := errorsMap[column]; !exists && string(value) == "null" {
+			if descriptor, ok := weave.Descriptors[column]; !ok || !descriptor.Nullable {
+				errorsMap[column] = errors.New("This field is required.")
+			}
+		}
+	}
+
+	weave.validateEnums(&record, errorsMap)
+	return weave.validatedPartial(&record, present, errorsMap)
+}
+
+// jsonPath returns the keys of a field in the JSON encoding of a model, such as ["Shipping", "City"] for a field of a
+// nested struct, or nil for fields that are not encoded. Fields promoted from embedded structs without a JSON name are
+// keyed at the level of the struct embedding them, as encoding/json does.
+func jsonPath(modelType reflect.Type, index []int) []string {
+	path := make([]string, 0, len(index))
+	for _, i := range index {
+		if modelType.Kind() == reflect.Pointer {
+			modelType = modelType.Elem()
+		}
+		field := modelType.Field(i)
+		modelType = field.Type
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return nil
+		}
+		if name == "" {
+			if field.Anonymous {
+				continue
+			}
+			name = field.Name
+		}
+		path = append(path, name)
+	}
+	return path
+}
+
+// jsonLookup returns the raw value at a path of keys in a JSON object, matching keys case-insensitively as
+// json.Unmarshal does. A null object along the path is returned as the value, as it clears the fields nested in it.
+func jsonLookup(raw map[string]json.RawMessage, path []string) (json.RawMessage, bool) {
+	for i, key := range path {
+		value, ok := raw[key]
+		if !ok {
+			for rawKey, rawValue := range raw {
+				if strings.EqualFold(rawKey, key) {
+					value, ok = rawValue, true
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+		if i == len(path)-1 || string(value) == "null" {
+			return value, true
+		}
+		raw = make(map[string]json.RawMessage)
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
 func (weave *Weave[T]) ValidateParams(r *http.Request) (map[string]any, error) {
 	data := make(map[string]any, 0)
 	errorsMap := make(map[string]error, 0)
@@ -273,6 +606,38 @@ func (weave *Weave[T]) ValidateParams(r *http.Request) (map[string]any, error) {
 	return data, nil
 }
 
+// ValidatePartial validates the fields present in a request body by content type, for partial updates such as PATCH.
+func (weave *Weave[T]) ValidatePartial(r *http.Request) (map[string]any, error) {
+	switch requestMediaType(r) {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return weave.ValidateEncodedPartial(r)
+	case "application/json":
+		return weave.ValidateJsonPartial(r)
+	}
+	return nil, ErrorBadRequest{Message: "Unsupported content type"}
+}
+
+// validatedPartial returns the present columns of a validated record, or the validation errors.
+func (weave *Weave[T]) validatedPartial(record *T, present []string, errorsMap map[string]error) (map[string]any, error) {
+	if len(errorsMap) > 0 {
+		return nil, FormErrors{
+			Errors:     errorsMap,
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	data, err := weave.ToMap(record)
+	if err != nil {
+		return nil, err
+	}
+	partial := make(map[string]any, len(present))
+	for _, column := range present {
+		if value, ok := data[column]; ok {
+			partial[column] = value
+		}
+	}
+	return partial, nil
+}
+
 func (weave *Weave[T]) Zero() T {
 	var zero T
 	return zero
@@ -335,27 +700,39 @@ func UseWith[T any](config WeaveConfig) *Weave[T] {
 
 	var primaryColumn string
 	var primaryField string
+	descriptors := make(map[string]*FieldDescriptor, 0)
 	fields := make(map[string]reflect.StructField, 0)
+	relations := make(map[string]WeaveRelation, 0)
+	orderedColumns := make([]string, 0)
+	orderedFields := make([]reflect.StructField, 0)
 
-	for _, field := range reflect.VisibleFields(modelType) {
-		if column, ok := field.Tag.Lookup("@"); ok {
-			if strings.HasPrefix(field.Type.String(), "trance.OneToMany[") {
-				fields[field.Name] = field
-			} else {
-				fields[column] = field
-				if field.Tag.Get("@primary") == "true" {
-					primaryColumn = column
-					primaryField = field.Name
-				}
+	walkFields(modelType, func(field reflect.StructField, column string) {
+		if relation, ok := newWeaveRelation(field, column); ok {
+			relations[field.Name] = relation
+		}
+		descriptor := newFieldDescriptor(field, column)
+		if descriptor.Kind == FieldKindOneToMany {
+			descriptors[field.Name] = descriptor
+			fields[field.Name] = field
+		} else {
+			descriptors[column] = descriptor
+			fields[column] = field
+			orderedColumns = append(orderedColumns, column)
+			orderedFields = append(orderedFields, field)
+			if descriptor.Primary {
+				primaryColumn = column
+				primaryField = field.Name
 			}
 		}
-	}
+	})
 
 	weave := &Weave[T]{
 		Config:        config,
+		Descriptors:   descriptors,
 		Fields:        fields,
 		PrimaryColumn: primaryColumn,
 		PrimaryField:  primaryField,
+		Relations:     relations,
 		Type:          modelType,
 	}
 	if config.Table == "" {
@@ -363,6 +740,8 @@ func UseWith[T any](config WeaveConfig) *Weave[T] {
 	} else {
 		weave.Table = config.Table
 	}
+	weave.Constraints = modelConstraints[T](weave.Table)
+	weave.Indexes = modelIndexes[T](weave.Table, orderedFields, orderedColumns)
 	if !config.NoCache {
 		weavesCache.Store(modelTypeStr, weave)
 	}
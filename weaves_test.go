@@ -2,7 +2,10 @@ package trance
 
 import (
 	"database/sql"
+	"net/http"
+	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -315,6 +318,347 @@ func TestModelToMap(t *testing.T) {
 	}
 }
 
+type testTimestamps struct {
+	CreatedAt time.Time    `@:"created_at"`
+	UpdatedAt sql.NullTime `@:"updated_at"`
+}
+type testAddress struct {
+	City   string `@:"city"`
+	Street string `@:"street"`
+}
+type testNestedModel struct {
+	testTimestamps
+	Billing  *testAddress `@prefix:"billing_"`
+	Id       int64        `@:"id" @primary:"true"`
+	Shipping testAddress  `@prefix:"shipping_"`
+}
+
+func TestModelNestedFields(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testNestedModel](WeaveConfig{NoCache: true})
+
+	columns := maps.Keys(weave.Fields)
+	sort.Strings(columns)
+	expectedColumns := []string{"billing_city", "billing_street", "created_at", "id", "shipping_city", "shipping_street", "updated_at"}
+	if !slices.Equal(columns, expectedColumns) {
+		t.Errorf("Expected '%+v', got '%+v'", expectedColumns, columns)
+	}
+	if name := weave.Fields["shipping_city"].Name; name != "Shipping.City" {
+		t.Errorf("Expected 'Shipping.City', got '%s'", name)
+	}
+
+	createdAt := time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC)
+	data := map[string]any{
+		"billing_city":    "Boston",
+		"billing_street":  "1 Main St",
+		"created_at":      createdAt,
+		"id":              int64(1),
+		"shipping_city":   "Denver",
+		"shipping_street": "2 Elm St",
+		"updated_at":      nil,
+	}
+	row, err := weave.ScanMap(data)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 1 || row.CreatedAt != createdAt || row.UpdatedAt.Valid || row.Billing == nil ||
+		row.Billing.City != "Boston" || row.Billing.Street != "1 Main St" ||
+		row.Shipping.City != "Denver" || row.Shipping.Street != "2 Elm St" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	actual, err := weave.ToMap(row)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !maps.Equal(actual, data) {
+		t.Errorf("Expected '%#v', got '%#v'", data, actual)
+	}
+
+	// Nil nested pointers read as zero values.
+	actual, err = weave.ToMap(&testNestedModel{Id: 2})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if actual["billing_city"] != "" {
+		t.Errorf("Expected empty billing city, got '%#v'", actual["billing_city"])
+	}
+
+	assertMapDeepEquals(t, weave.ToJsonMap(row), map[string]any{
+		"billing.city":    "Boston",
+		"billing.street":  "1 Main St",
+		"createdat":       createdAt,
+		"id":              int64(1),
+		"shipping.city":   "Denver",
+		"shipping.street": "2 Elm St",
+		"updatedat":       nil,
+	})
+}
+
+type testJsonModel struct {
+	Id       int64                  `@:"id" @primary:"true"`
+	Labels   []string               `@:"labels" @json:"true"`
+	Metadata *map[string]any        `@:"metadata" @json:"true"`
+	Settings JSON[testJsonSettings] `@:"settings"`
+}
+type testJsonSettings struct {
+	Theme string `json:"theme"`
+}
+
+func TestModelJsonFields(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testJsonModel](WeaveConfig{NoCache: true})
+
+	row, err := weave.ScanMap(map[string]any{
+		"id":       int64(1),
+		"labels":   []byte(`["a","b"]`),
+		"metadata": nil,
+		"settings": `{"theme":"dark"}`,
+	})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 1 || !slices.Equal(row.Labels, []string{"a", "b"}) || row.Metadata != nil || row.Settings.Data.Theme != "dark" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	actual, err := weave.ToMap(row)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if actual["labels"] != `["a","b"]` || actual["metadata"] != nil || actual["settings"] != `{"theme":"dark"}` {
+		t.Errorf("Unexpected map '%#v'", actual)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "labels", "metadata", "settings"}).
+		AddRow(2, `["c"]`, `{"foo":"bar"}`, `{"theme":"light"}`)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	rs, _ := db.Query("SELECT")
+	defer rs.Close()
+	columns, _ := rs.Columns()
+
+	if !rs.Next() {
+		t.Fatal("Expected a row")
+	}
+	row, err = weave.ScanColumns(rs, columns)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Id != 2 || !slices.Equal(row.Labels, []string{"c"}) || row.Metadata == nil ||
+		(*row.Metadata)["foo"] != "bar" || row.Settings.Data.Theme != "light" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+}
+
+type testNullableModel struct {
+	Count    *int64           `@:"count"`
+	Id       int64            `@:"id" @primary:"true"`
+	Name     *string          `@:"name"`
+	Nickname sql.Null[string] `@:"nickname"`
+	Title    string           `@:"title"`
+}
+
+func TestModelNullableFields(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testNullableModel](WeaveConfig{NoCache: true})
+
+	row, err := weave.ScanMap(map[string]any{
+		"count":    int64(3),
+		"id":       int64(1),
+		"name":     "foo",
+		"nickname": "bar",
+		"title":    "baz",
+	})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Count == nil || *row.Count != 3 || row.Name == nil || *row.Name != "foo" ||
+		!row.Nickname.Valid || row.Nickname.V != "bar" || row.Title != "baz" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	actual, err := weave.ToMap(row)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := map[string]any{"count": int64(3), "id": int64(1), "name": "foo", "nickname": "bar", "title": "baz"}
+	if !maps.Equal(actual, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, actual)
+	}
+
+	actual, err = weave.ToMap(&testNullableModel{Id: 2, Title: "baz"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected = map[string]any{"count": nil, "id": int64(2), "name": nil, "nickname": nil, "title": "baz"}
+	if !maps.Equal(actual, expected) {
+		t.Errorf("Expected '%#v', got '%#v'", expected, actual)
+	}
+	assertMapDeepEquals(t, weave.ToJsonMap(row), map[string]any{
+		"count":    int64(3),
+		"id":       int64(1),
+		"name":     "foo",
+		"nickname": "bar",
+		"title":    "baz",
+	})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"count", "id", "name", "nickname", "title"}).
+		AddRow(nil, 3, nil, nil, "baz"))
+	rs, _ := db.Query("SELECT")
+	defer rs.Close()
+	columns, _ := rs.Columns()
+	if !rs.Next() {
+		t.Fatal("Expected a row")
+	}
+	row, err = weave.ScanColumns(rs, columns)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Count != nil || row.Id != 3 || row.Name != nil || row.Nickname.Valid || row.Title != "baz" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	// Nullable fields are optional on forms.
+	form := weave.Clone()
+	delete(form.Descriptors, "id")
+	delete(form.Fields, "id")
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=foo&nickname=&title=baz"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	row, err = form.Validate(request)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if row.Count != nil || row.Name == nil || *row.Name != "foo" || row.Nickname.Valid || row.Title != "baz" {
+		t.Errorf("Unexpected row '%+v'", row)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":4}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err = weave.Validate(request)
+	formErrors, ok := err.(FormErrors)
+	if !ok {
+		t.Fatalf("Expected FormErrors, got '%#v'", err)
+	}
+	if _, ok := formErrors.Errors["title"]; !ok || len(formErrors.Errors) != 1 {
+		t.Errorf("Expected only 'title' to be required, got '%#v'", formErrors.Errors)
+	}
+
+	// Named database/sql null types remain required.
+	type testNamedNullModel struct {
+		Nickname sql.NullString `@:"nickname"`
+	}
+	request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err = UseWith[testNamedNullModel](WeaveConfig{NoCache: true}).Validate(request)
+	if formErrors, ok := err.(FormErrors); !ok || formErrors.Errors["nickname"] == nil {
+		t.Errorf("Expected 'nickname' to be required, got '%#v'", err)
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+	type testModel struct {
+		Active bool    `@:"active"`
+		Count  int64   `@:"count"`
+		Name   *string `@:"name"`
+		Title  string  `@:"title"`
+	}
+	defer PurgeWeaves()
+	weave := UseWith[testModel](WeaveConfig{NoCache: true})
+
+	expected := []struct {
+		body        string
+		contentType string
+		errors      []string
+		expected    map[string]any
+	}{
+		{body: "", contentType: "application/json", expected: map[string]any{}},
+		{body: `{"active": false, "count": 0}`, contentType: "application/json", expected: map[string]any{"active": false, "count": int64(0)}},
+		{body: `{"Name": null, "title": "foo"}`, contentType: "application/json", expected: map[string]any{"name": nil, "title": "foo"}},
+		{body: `{"title": null}`, contentType: "application/json", errors: []string{"title"}},
+		{body: `{"count": "x"}`, contentType: "application/json", errors: []string{"count"}},
+		{body: `{"unknown": 1}`, contentType: "application/json", errors: []string{"_global_"}},
+		{body: `[]`, contentType: "application/json", errors: []string{"_global_"}},
+		{body: "name=&title=foo", contentType: "application/x-www-form-urlencoded", expected: map[string]any{"name": nil, "title": "foo"}},
+		{body: "title=", contentType: "application/x-www-form-urlencoded", errors: []string{"title"}},
+		{body: `{"title": "foo"}`, contentType: "application/json; charset=utf-8", expected: map[string]any{"title": "foo"}},
+		{body: "--x\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nfoo\r\n--x--\r\n", contentType: "multipart/form-data; boundary=x", expected: map[string]any{"title": "foo"}},
+	}
+	for _, e := range expected {
+		request := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(e.body))
+		request.Header.Set("Content-Type", e.contentType)
+		actual, err := weave.ValidatePartial(request)
+		if e.errors != nil {
+			formErrors, ok := err.(FormErrors)
+			if !ok {
+				t.Errorf("%s: expected FormErrors, got '%#v'", e.body, err)
+				continue
+			}
+			keys := maps.Keys(formErrors.Errors)
+			sort.Strings(keys)
+			if !slices.Equal(keys, e.errors) {
+				t.Errorf("%s: expected errors for '%v', got '%#v'", e.body, e.errors, formErrors.Errors)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error: %s", e.body, err)
+		} else {
+			assertMapDeepEquals(t, actual, e.expected)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader("{}"))
+	request.Header.Set("Content-Type", "text/plain")
+	if _, err := weave.ValidatePartial(request); err == nil || err.Error() != "Unsupported content type" {
+		t.Errorf("Expected unsupported content type, got '%#v'", err)
+	}
+}
+
+func TestValidatePartialNested(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testNestedModel](WeaveConfig{NoCache: true})
+
+	expected := map[string]map[string]any{
+		`{"Shipping": {"City": "Paris"}}`:                  {"shipping_city": "Paris"},
+		`{"shipping": {"city": "Paris", "street": "Rue"}}`: {"shipping_city": "Paris", "shipping_street": "Rue"},
+		`{"Billing": {"City": "Boston"}}`:                  {"billing_city": "Boston"},
+	}
+	for body, e := range expected {
+		request := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		actual, err := weave.ValidatePartial(request)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", body, err)
+			continue
+		}
+		assertMapDeepEquals(t, actual, e)
+	}
+
+	request := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"Shipping": {"City": 1}}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err := weave.ValidatePartial(request)
+	if formErrors, ok := err.(FormErrors); !ok || formErrors.Errors["shipping_city"] == nil {
+		t.Errorf("Expected error for 'shipping_city', got '%#v'", err)
+	}
+
+	request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"UpdatedAt": null}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err = weave.ValidatePartial(request)
+	if formErrors, ok := err.(FormErrors); !ok || formErrors.Errors["updated_at"] == nil {
+		t.Errorf("Expected error for 'updated_at', got '%#v'", err)
+	}
+}
+
 func TestRegister(t *testing.T) {
 	type testModel struct {
 		Id   int64  `@:"id" @primary:"true"`
@@ -350,6 +694,60 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestScanColumns(t *testing.T) {
+	defer PurgeWeaves()
+	weave := UseWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "edited_at", "group_id"}).
+		AddRow(1, "foo", time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC), 10).
+		AddRow(2, "bar", nil, nil)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	rs, _ := db.Query("SELECT")
+	defer rs.Close()
+	columns, _ := rs.Columns()
+
+	expected := []testAccountsModelToMap{
+		{
+			Id:   1,
+			Name: "foo",
+			EditedAt: sql.NullTime{
+				Time:  time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC),
+				Valid: true,
+			},
+			Group: NullForeignKey[testGroupsModelToMap]{
+				Row:   &testGroupsModelToMap{Id: 10},
+				Valid: true,
+			},
+		},
+		{Id: 2, Name: "bar"},
+	}
+	i := 0
+	for rs.Next() {
+		actual, err := weave.ScanColumns(rs, columns)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if actual.Id != expected[i].Id ||
+			actual.Name != expected[i].Name ||
+			actual.EditedAt != expected[i].EditedAt ||
+			actual.Group.Valid != expected[i].Group.Valid ||
+			(actual.Group.Valid && actual.Group.Row.Id != expected[i].Group.Row.Id) {
+			t.Errorf("Expected '%+v', got '%+v'", expected[i], actual)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d rows, got %d", len(expected), i)
+	}
+}
+
 func TestScanToMap(t *testing.T) {
 	type testAccounts struct {
 		EditedAt sql.NullTime `@:"edited_at"`
@@ -438,3 +836,96 @@ func TestUse(t *testing.T) {
 		t.Errorf("Expected '%s', got '%s'", expectedTable, groups.Table)
 	}
 }
+
+func benchmarkScanRows(b *testing.B, scan func(*sql.Rows, []string) error) {
+	const size = 1000
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatal("failed to open sqlmock database:", err)
+	}
+	defer db.Close()
+	editedAt := time.Date(2009, time.January, 2, 3, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := sqlmock.NewRows([]string{"id", "name", "edited_at", "group_id"})
+		for j := 0; j < size; j++ {
+			rows.AddRow(int64(j), "foo", editedAt, int64(j%10))
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+		rs, _ := db.Query("SELECT")
+		columns, _ := rs.Columns()
+		b.StartTimer()
+
+		for rs.Next() {
+			if err := scan(rs, columns); err != nil {
+				b.Fatal("Unexpected error:", err)
+			}
+		}
+		rs.Close()
+	}
+}
+
+func BenchmarkScanColumns(b *testing.B) {
+	defer PurgeWeaves()
+	weave := UseWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+	benchmarkScanRows(b, func(rs *sql.Rows, columns []string) error {
+		_, err := weave.ScanColumns(rs, columns)
+		return err
+	})
+}
+
+func BenchmarkScanFieldsToMap(b *testing.B) {
+	defer PurgeWeaves()
+	weave := UseWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+	benchmarkScanRows(b, func(rs *sql.Rows, _ []string) error {
+		data, err := ScanFieldsToMap(rs, weave.Fields)
+		if err != nil {
+			return err
+		}
+		_, err = weave.ScanMap(data)
+		return err
+	})
+}
+
+func BenchmarkScanToMap(b *testing.B) {
+	defer PurgeWeaves()
+	query := QueryWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+	benchmarkScanRows(b, func(rs *sql.Rows, _ []string) error {
+		data, err := query.ScanToMap(rs)
+		if err != nil {
+			return err
+		}
+		_, err = query.Weave.ScanMap(data)
+		return err
+	})
+}
+
+func BenchmarkToMap(b *testing.B) {
+	defer PurgeWeaves()
+	weave := UseWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+	row := &testAccountsModelToMap{
+		Id:   1,
+		Name: "foo",
+		Group: NullForeignKey[testGroupsModelToMap]{
+			Row:   &testGroupsModelToMap{Id: 10},
+			Valid: true,
+		},
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := weave.ToMap(row); err != nil {
+			b.Fatal("Unexpected error:", err)
+		}
+	}
+}
+
+func BenchmarkToJsonMap(b *testing.B) {
+	defer PurgeWeaves()
+	weave := UseWith[testAccountsModelToMap](WeaveConfig{NoCache: true})
+	row := &testAccountsModelToMap{Id: 1, Name: "foo"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		weave.ToJsonMap(row)
+	}
+}